@@ -9,7 +9,64 @@ const (
 )
 
 const (
-	Github Provider = "Github"
+	Git             Provider = "Git"
+	Github          Provider = "Github"
+	GitLab          Provider = "Gitlab"
+	Bitbucket       Provider = "Bitbucket"
+	BitbucketServer Provider = "BitbucketServer"
+	AzureRepo       Provider = "AzureRepo"
+	Gitea           Provider = "Gitea"
+	Forgejo         Provider = "Forgejo"
+	Harness         Provider = "Harness"
+	CodeCommit      Provider = "Codecommit"
+	Gerrit          Provider = "Gerrit"
+	// GenericGit is a git server without a REST API, such as cgit, gitolite
+	// or git-http-backend. Unlike Git, it does not trigger provider detection.
+	GenericGit Provider = "GenericGit"
+)
+
+const (
+	APIAccessValidation = "api_access"
+)
+
+const (
+	EntryFile      EntryType = "file"
+	EntryDir       EntryType = "dir"
+	EntrySubmodule EntryType = "submodule"
+	EntrySymlink   EntryType = "symlink"
+)
+
+const (
+	FileAdded    FileStatus = "added"
+	FileModified FileStatus = "modified"
+	FileRenamed  FileStatus = "renamed"
+	FileDeleted  FileStatus = "deleted"
+)
+
+const (
+	FileCreate FileAction = "create"
+	FileUpdate FileAction = "update"
+	FileDelete FileAction = "delete"
+)
+
+const (
+	RefBranch RefType = "branch"
+	RefTag    RefType = "tag"
+	RefCommit RefType = "commit"
+	// RefOther is any other ref, such as refs/pull/* or refs/merge-requests/*
+	RefOther RefType = "other"
+)
+
+const (
+	PullRequestOpen   PullRequestState = "open"
+	PullRequestClosed PullRequestState = "closed"
+	PullRequestMerged PullRequestState = "merged"
+	PullRequestAll    PullRequestState = "all"
+)
+
+const (
+	EncodingUTF8   = "utf-8"
+	EncodingBase64 = "base64"
 )
 
 const (
@@ -22,10 +79,11 @@ const (
 )
 
 const (
-	HTTPAuthPassword  HTTPAuthMethod = "UsernamePassword"
-	HTTPAuthToken     HTTPAuthMethod = "UsernameToken"
-	HTTPAuthAnonymous HTTPAuthMethod = "Anonymous"
-	HTTPAuthGithubApp HTTPAuthMethod = "GithubApp"
+	HTTPAuthPassword       HTTPAuthMethod = "UsernamePassword"
+	HTTPAuthToken          HTTPAuthMethod = "UsernameToken"
+	HTTPAuthAnonymous      HTTPAuthMethod = "Anonymous"
+	HTTPAuthGithubApp      HTTPAuthMethod = "GithubApp"
+	HTTPAuthAWSCredentials HTTPAuthMethod = "AWSCredentials"
 )
 
 const (
@@ -35,6 +93,7 @@ const (
 )
 
 const (
-	APIAccessToken     APIAccessType = "Token"
-	APIAccessGithubApp APIAccessType = "GithubApp"
+	APIAccessToken         APIAccessType = "Token"
+	APIAccessUsernameToken APIAccessType = "UsernameToken"
+	APIAccessGithubApp     APIAccessType = "GithubApp"
 )
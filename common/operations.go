@@ -0,0 +1,488 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPage    = 1
+	DefaultPerPage = 30
+	MaxPerPage     = 100
+)
+
+type Response struct {
+	Status       ResponseStatus `json:"status"`
+	Errors       []ErrorDetail  `json:"errors"`
+	ErrorSummary string         `json:"error_summary"`
+}
+
+type PageInput struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+}
+
+type ListBranchesInput struct {
+	PageInput
+	Filter string `json:"filter"`
+}
+
+type Branch struct {
+	Name string `json:"name"`
+	Sha  string `json:"sha"`
+}
+
+type ListBranchesResponse struct {
+	Response
+	Branches []Branch `json:"branches"`
+	NextPage int      `json:"next_page,omitempty"`
+}
+
+type CreateBranchInput struct {
+	Name string `json:"name"`
+	Ref  string `json:"ref"`
+}
+
+type CreateBranchResponse struct {
+	Response
+	Branch *Branch `json:"branch,omitempty"`
+}
+
+type DeleteBranchInput struct {
+	Name string `json:"name"`
+}
+
+type DeleteBranchResponse struct {
+	Response
+}
+
+type ListTagsInput struct {
+	PageInput
+	Filter string `json:"filter"`
+}
+
+type Signature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+type Tag struct {
+	Name      string     `json:"name"`
+	Sha       string     `json:"sha"`
+	CommitSha string     `json:"commit_sha"`
+	Annotated bool       `json:"annotated"`
+	Tagger    *Signature `json:"tagger,omitempty"`
+	Date      *time.Time `json:"date,omitempty"`
+}
+
+type ListTagsResponse struct {
+	Response
+	Tags     []Tag `json:"tags"`
+	NextPage int   `json:"next_page,omitempty"`
+}
+
+type GetFileContentInput struct {
+	Path string `json:"path"`
+	Ref  string `json:"ref"`
+}
+
+type FileContent struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Encoding  string `json:"encoding"`
+	BlobSha   string `json:"blob_sha"`
+	Size      int64  `json:"size"`
+	CommitSha string `json:"commit_sha"`
+}
+
+type GetFileContentResponse struct {
+	Response
+	File *FileContent `json:"file,omitempty"`
+}
+
+type CommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type UpsertFileInput struct {
+	Path     string `json:"path"`
+	Branch   string `json:"branch"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+	Message  string `json:"message"`
+	// BlobSha is the blob of the file being updated. When set, the
+	// update fails if the file has changed since.
+	BlobSha string        `json:"blob_sha"`
+	Author  *CommitAuthor `json:"author"`
+}
+
+type UpsertFileResponse struct {
+	Response
+	CommitSha string `json:"commit_sha,omitempty"`
+	BlobSha   string `json:"blob_sha,omitempty"`
+}
+
+type CommitFilesInput struct {
+	Branch  string        `json:"branch"`
+	Message string        `json:"message"`
+	Author  *CommitAuthor `json:"author"`
+	Files   []FileInput   `json:"files"`
+}
+
+type FileInput struct {
+	Action   FileAction `json:"action"`
+	Path     string     `json:"path"`
+	Content  string     `json:"content"`
+	Encoding string     `json:"encoding"`
+	// BlobSha is the blob of the file being updated or deleted. When
+	// set, the commit fails if the file has changed since.
+	BlobSha string `json:"blob_sha"`
+}
+
+type CommitFilesResponse struct {
+	Response
+	CommitSha string `json:"commit_sha,omitempty"`
+}
+
+type ListTreeInput struct {
+	Path      string `json:"path"`
+	Ref       string `json:"ref"`
+	Recursive bool   `json:"recursive"`
+	MaxDepth  int    `json:"max_depth"`
+	Glob      string `json:"glob"`
+}
+
+type TreeEntry struct {
+	Name string    `json:"name"`
+	Path string    `json:"path"`
+	Type EntryType `json:"type"`
+	Size int64     `json:"size,omitempty"`
+	Sha  string    `json:"sha"`
+}
+
+type ListTreeResponse struct {
+	Response
+	CommitSha string      `json:"commit_sha"`
+	Entries   []TreeEntry `json:"entries"`
+}
+
+type ListCommitsInput struct {
+	PageInput
+	Ref   string     `json:"ref"`
+	Path  string     `json:"path"`
+	Since *time.Time `json:"since"`
+	Until *time.Time `json:"until"`
+}
+
+type GetCommitInput struct {
+	Ref string `json:"ref"`
+}
+
+type Commit struct {
+	Sha       string        `json:"sha"`
+	Message   string        `json:"message"`
+	Author    Signature     `json:"author"`
+	Committer Signature     `json:"committer"`
+	Parents   []string      `json:"parents,omitempty"`
+	Files     []ChangedFile `json:"files,omitempty"`
+}
+
+type ChangedFile struct {
+	Path         string     `json:"path"`
+	PreviousPath string     `json:"previous_path,omitempty"`
+	Status       FileStatus `json:"status"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+}
+
+type ListCommitsResponse struct {
+	Response
+	Commits  []Commit `json:"commits"`
+	NextPage int      `json:"next_page,omitempty"`
+}
+
+type GetCommitResponse struct {
+	Response
+	Commit *Commit `json:"commit,omitempty"`
+}
+
+type CompareRefsInput struct {
+	Base string `json:"base"`
+	Head string `json:"head"`
+}
+
+type CompareRefsResponse struct {
+	Response
+	BaseSha      string        `json:"base_sha,omitempty"`
+	HeadSha      string        `json:"head_sha,omitempty"`
+	MergeBaseSha string        `json:"merge_base_sha,omitempty"`
+	AheadBy      int           `json:"ahead_by"`
+	BehindBy     int           `json:"behind_by"`
+	Files        []ChangedFile `json:"files,omitempty"`
+	// FilesTruncated is set when the provider lists only part of the
+	// changed files
+	FilesTruncated bool `json:"files_truncated,omitempty"`
+}
+
+type CreatePullRequestInput struct {
+	Source    string   `json:"source"`
+	Target    string   `json:"target"`
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Draft     bool     `json:"draft"`
+	Reviewers []string `json:"reviewers"`
+	Labels    []string `json:"labels"`
+}
+
+type CreatePullRequestResponse struct {
+	Response
+	Number int    `json:"number,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+type ListPullRequestsInput struct {
+	PageInput
+	State PullRequestState `json:"state"`
+	Head  string           `json:"head"`
+	Base  string           `json:"base"`
+}
+
+type GetPullRequestInput struct {
+	Number int `json:"number"`
+}
+
+type ListPullRequestFilesInput struct {
+	PageInput
+	Number int `json:"number"`
+}
+
+type PullRequest struct {
+	Number  int              `json:"number"`
+	Title   string           `json:"title"`
+	Body    string           `json:"body"`
+	State   PullRequestState `json:"state"`
+	Draft   bool             `json:"draft"`
+	Source  string           `json:"source"`
+	Target  string           `json:"target"`
+	HeadSha string           `json:"head_sha"`
+	BaseSha string           `json:"base_sha,omitempty"`
+	Author  string           `json:"author"`
+	URL     string           `json:"url"`
+	Labels  []string         `json:"labels,omitempty"`
+	Created time.Time        `json:"created"`
+	Updated time.Time        `json:"updated"`
+	// Mergeable is only reported when getting a single pull request,
+	// and is unknown while the provider is still computing it.
+	Mergeable      *bool  `json:"mergeable,omitempty"`
+	MergeableState string `json:"mergeable_state,omitempty"`
+}
+
+type ListPullRequestsResponse struct {
+	Response
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextPage     int           `json:"next_page,omitempty"`
+}
+
+type GetPullRequestResponse struct {
+	Response
+	PullRequest *PullRequest `json:"pull_request,omitempty"`
+}
+
+type ListPullRequestFilesResponse struct {
+	Response
+	Files    []ChangedFile `json:"files"`
+	NextPage int           `json:"next_page,omitempty"`
+}
+
+type WebhookInput struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Target        string   `json:"target"`
+	Secret        string   `json:"secret"`
+	Events        []string `json:"events"`
+	SkipSSLVerify bool     `json:"skip_ssl_verify"`
+}
+
+type ListWebhooksInput struct {
+	PageInput
+}
+
+type DeleteWebhookInput struct {
+	ID     string `json:"id"`
+	Target string `json:"target"`
+}
+
+type Webhook struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	Target        string   `json:"target"`
+	Events        []string `json:"events"`
+	Active        bool     `json:"active"`
+	SkipSSLVerify bool     `json:"skip_ssl_verify"`
+}
+
+type WebhookResponse struct {
+	Response
+	Webhook *Webhook `json:"webhook,omitempty"`
+	// Created is false when an existing webhook for the same
+	// target was updated instead.
+	Created bool `json:"created"`
+}
+
+type ListWebhooksResponse struct {
+	Response
+	Webhooks []Webhook `json:"webhooks"`
+	NextPage int       `json:"next_page,omitempty"`
+}
+
+type DeleteWebhookResponse struct {
+	Response
+	Deleted bool `json:"deleted"`
+}
+
+type ListRepositoriesInput struct {
+	PageInput
+	Namespace string `json:"namespace"`
+	// Filter keeps the repositories whose name contains it. The provider
+	// searches for them within a namespace on Github, and across every
+	// repository the credentials can access on Gitlab, Bitbucket, Bitbucket
+	// Server and Gerrit. Otherwise only the requested page is filtered, which
+	// may then hold fewer repositories than requested, or none, with more
+	// pages to come.
+	Filter string `json:"filter"`
+}
+
+type Repository struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+	Visibility    string `json:"visibility,omitempty"`
+	Private       bool   `json:"private"`
+	Archived      bool   `json:"archived"`
+	CloneURL      string `json:"clone_url,omitempty"`
+	SSHURL        string `json:"ssh_url,omitempty"`
+	URL           string `json:"url,omitempty"`
+	Disabled      bool   `json:"disabled,omitempty"`
+	Fork          bool   `json:"fork,omitempty"`
+	Parent        string `json:"parent,omitempty"`
+	// Size is in bytes, when the provider reports it.
+	Size        int64        `json:"size,omitempty"`
+	Permissions *Permissions `json:"permissions,omitempty"`
+}
+
+type Permissions struct {
+	Pull  bool `json:"pull"`
+	Push  bool `json:"push"`
+	Admin bool `json:"admin"`
+}
+
+type GetRepositoryResponse struct {
+	Response
+	Repository *Repository `json:"repository,omitempty"`
+}
+
+type ListRepositoriesResponse struct {
+	Response
+	Repositories []Repository `json:"repositories"`
+	// NextPage is set while there are more pages, even when a filter left
+	// the current page empty.
+	NextPage int `json:"next_page,omitempty"`
+}
+
+type ResolveRefInput struct {
+	Ref string `json:"ref"`
+}
+
+type ResolveRefResponse struct {
+	Response
+	Ref           string  `json:"ref,omitempty"`
+	RefType       RefType `json:"ref_type,omitempty"`
+	Sha           string  `json:"sha,omitempty"`
+	DefaultBranch string  `json:"default_branch,omitempty"`
+}
+
+// NewSuccessResponse returns the envelope of a successful operation.
+func NewSuccessResponse() Response {
+	return Response{
+		Status: Success,
+	}
+}
+
+// NewFailureResponse returns the envelope of a failed operation.
+func NewFailureResponse(summary string, err error) Response {
+	return Response{
+		Status:       Failure,
+		Errors:       []ErrorDetail{{Message: err.Error()}},
+		ErrorSummary: summary,
+	}
+}
+
+// NewPartialResponse returns the envelope of an operation that succeeded
+// only in part, with a detail for each error joined into err.
+func NewPartialResponse(summary string, err error) Response {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	details := []ErrorDetail{}
+	for _, err := range errs {
+		details = append(details, ErrorDetail{Message: err.Error()})
+	}
+	return Response{
+		Status:       Partial,
+		Errors:       details,
+		ErrorSummary: summary,
+	}
+}
+
+// DecodeInput unmarshals the operation params of the request into v.
+// Operations without params leave v untouched.
+func DecodeInput(input json.RawMessage, v interface{}) error {
+	if len(input) == 0 || string(input) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(input, v); err != nil {
+		return fmt.Errorf("failed to decode operation params: %w", err)
+	}
+	return nil
+}
+
+// Normalize applies the default page and page size and caps the
+// page size to what the providers accept.
+func (p *PageInput) Normalize() {
+	if p.Page < 1 {
+		p.Page = DefaultPage
+	}
+	if p.PerPage < 1 {
+		p.PerPage = DefaultPerPage
+	}
+	if p.PerPage > MaxPerPage {
+		p.PerPage = MaxPerPage
+	}
+}
+
+// Bounds returns the slice bounds of the page within a list of the given
+// length, along with the next page number or zero on the last page. It is
+// used to paginate lists that are fetched in full, such as git refs.
+func (p *PageInput) Bounds(length int) (int, int, int) {
+	start := (p.Page - 1) * p.PerPage
+	if start > length {
+		start = length
+	}
+	end := start + p.PerPage
+	if end >= length {
+		return start, length, 0
+	}
+	return start, end, p.Page + 1
+}
+
+// MatchFilter returns true when the name contains the filter, ignoring
+// case. An empty filter matches every name.
+func MatchFilter(name, filter string) bool {
+	return filter == "" || strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
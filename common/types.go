@@ -1,5 +1,7 @@
 package common
 
+import "encoding/json"
+
 type ResponseStatus string
 type Provider string
 type GitAuthType string
@@ -7,11 +9,17 @@ type SSHAuthMechanism string
 type HTTPAuthMethod string
 type SSHAuthMethod string
 type APIAccessType string
+type EntryType string
+type FileStatus string
+type FileAction string
+type RefType string
+type PullRequestState string
 
 type RequestData struct {
 	Provider  Provider            `json:"connector_type"`
 	Operation string              `json:"connector_operation"`
 	Params    *GitConnectorParams `json:"connector_params"`
+	Input     json.RawMessage     `json:"operation_params"`
 }
 
 type GitConnectorParams struct {
@@ -23,11 +31,12 @@ type GitConnectorParams struct {
 }
 
 type HTTPAuth struct {
-	AuthMethod HTTPAuthMethod `json:"auth_method"`
-	Username   string         `json:"username"`
-	Token      string         `json:"token"`
-	Password   string         `json:"password"`
-	GithubApp  *GithubApp     `json:"github_app_auth"`
+	AuthMethod HTTPAuthMethod  `json:"auth_method"`
+	Username   string          `json:"username"`
+	Token      string          `json:"token"`
+	Password   string          `json:"password"`
+	GithubApp  *GithubApp      `json:"github_app_auth"`
+	AWS        *AWSCredentials `json:"aws_credentials"`
 }
 
 type GithubApp struct {
@@ -37,6 +46,13 @@ type GithubApp struct {
 	GithubUrl         string `json:"github_url"`
 }
 
+type AWSCredentials struct {
+	AccessKeyId     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	SessionToken    string `json:"session_token"`
+	Region          string `json:"region"`
+}
+
 type SSHAuth struct {
 	AuthMechanism      SSHAuthMechanism `json:"auth_mechanism"`
 	SshKeyAuthMethod   SSHAuthMethod    `json:"ssh_key_auth_method"`
@@ -52,6 +68,7 @@ type APIAccess struct {
 	AccessType APIAccessType `json:"access_type"`
 	Endpoint   string        `json:"endpoint"`
 	ProxyURL   string        `json:"proxy_url"`
+	Username   string        `json:"username"`
 	Token      string        `json:"token"`
 	GithubApp  *GithubApp    `json:"github_app"`
 }
@@ -59,6 +76,9 @@ type ValidationResponse struct {
 	Status       ResponseStatus `json:"status"`
 	Errors       []ErrorDetail  `json:"errors"`
 	ErrorSummary string         `json:"error_summary"`
+	Provider     Provider       `json:"detected_connector_type,omitempty"`
+	APIEndpoint  string         `json:"detected_api_endpoint,omitempty"`
+	Skipped      []string       `json:"skipped_validations,omitempty"`
 }
 
 type ErrorDetail struct {
@@ -0,0 +1,144 @@
+package gitclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// DoJSON sends a request to a provider API endpoint that go-scm does not
+// cover, using the base URL and the authenticated transport of the client.
+// The request body is encoded from in and the response body decoded into out,
+// either of which may be nil.
+func DoJSON(ctx context.Context, client *scm.Client, method, path string, in, out interface{}) (*scm.Response, error) {
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: map[string][]string{
+			"Accept": {"application/json"},
+		},
+	}
+	// if we are posting or putting data, we need to
+	// write it to the body of the request.
+	if in != nil {
+		buf := new(bytes.Buffer)
+		if err := json.NewEncoder(buf).Encode(in); err != nil {
+			return nil, err
+		}
+		req.Header["Content-Type"] = []string{"application/json"}
+		req.Body = buf
+	}
+
+	res, err := client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.Status > 300 {
+		return res, apiError(res)
+	}
+	if out == nil || res.Status == http.StatusNoContent {
+		return res, nil
+	}
+	return res, json.NewDecoder(res.Body).Decode(out)
+}
+
+// apiError builds an error from the message of an API error response,
+// falling back to the status text when the body carries no message. Not
+// found responses wrap scm.ErrNotFound, for callers to tell them apart.
+func apiError(res *scm.Response) error {
+	err := errors.New(http.StatusText(res.Status))
+	if res.Status == http.StatusNotFound {
+		err = scm.ErrNotFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	out := struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}{}
+	var message string
+	if json.Unmarshal(body, &out) == nil {
+		message = out.Message
+		if message == "" {
+			message = out.Error
+		}
+	} else if text := strings.TrimSpace(string(body)); !strings.HasPrefix(text, "<") {
+		message = text
+	}
+	if message == "" || message == err.Error() {
+		return err
+	}
+	return fmt.Errorf("%w: %s", err, message)
+}
+
+// EscapePath escapes each segment of a path, such as that of a file or a
+// branch, keeping the slashes.
+func EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// IsNotFound reports whether an API call failed because the resource does
+// not exist. The go-scm drivers return errors of their own types, so the
+// status of the response is checked as well.
+func IsNotFound(res *scm.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, scm.ErrNotFound) || (res != nil && res.Status == http.StatusNotFound)
+}
+
+// ResolveCommit returns the SHA of the commit a branch, tag or SHA points
+// to, defaulting to the head of the default branch of the repository.
+func ResolveCommit(ctx context.Context, client *scm.Client, repo, ref string) (string, error) {
+	commit, err := FindCommit(ctx, client, repo, ref)
+	if err != nil {
+		return "", err
+	}
+	return commit.Sha, nil
+}
+
+// FindCommit returns the commit a branch, tag or SHA points to, defaulting
+// to the head of the default branch of the repository. Azure only finds
+// commits by their SHA, so scm.ErrNotSupported is returned for its branches
+// and tags.
+func FindCommit(ctx context.Context, client *scm.Client, repo, ref string) (*scm.Commit, error) {
+	ref, err := DefaultRef(ctx, client, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	if client.Driver == scm.DriverAzure && !plumbing.IsHash(ref) {
+		return nil, scm.ErrNotSupported
+	}
+	commit, _, err := client.Git.FindCommit(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// DefaultRef returns the ref, or the default branch of the repository
+// when the ref is empty.
+func DefaultRef(ctx context.Context, client *scm.Client, repo, ref string) (string, error) {
+	if ref != "" {
+		return ref, nil
+	}
+	repository, _, err := client.Repositories.Find(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	return repository.Branch, nil
+}
@@ -0,0 +1,117 @@
+package gitclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	azureHost       = "dev.azure.com"
+	azureLegacyHost = ".visualstudio.com"
+	azureEndpoint   = "https://dev.azure.com"
+)
+
+type AzureRepo struct {
+	Organization string
+	Project      string
+	Name         string
+}
+
+// ParseAzureRepo extracts the organization, project and repository name from
+// an Azure Repos clone URL. Both the HTTP form
+// https://dev.azure.com/{org}/{project}/_git/{repo} and the SSH form
+// git@ssh.dev.azure.com:v3/{org}/{project}/{repo} are supported, as well as
+// the legacy {org}.visualstudio.com hosts.
+func ParseAzureRepo(repo string) (*AzureRepo, error) {
+	if strings.HasPrefix(repo, "git@") || strings.HasPrefix(repo, "ssh://") || strings.Contains(repo, "vs-ssh.visualstudio.com:") {
+		return parseAzureSSHRepo(repo)
+	}
+	return parseAzureHTTPRepo(repo)
+}
+
+// parseAzureHTTPRepo parses https://dev.azure.com/{org}/{project}/_git/{repo}
+// and https://{org}.visualstudio.com/[DefaultCollection/]{project}/_git/{repo}
+func parseAzureHTTPRepo(repo string) (*AzureRepo, error) {
+	u, err := url.Parse(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Azure repository url %s: %w", repo, err)
+	}
+	parts := splitPath(u.EscapedPath())
+
+	var org string
+	switch {
+	case strings.EqualFold(u.Hostname(), azureHost):
+		if len(parts) == 0 {
+			return nil, fmt.Errorf("Azure repository url %s is missing the organization", repo)
+		}
+		org, parts = parts[0], parts[1:]
+	case strings.HasSuffix(strings.ToLower(u.Hostname()), azureLegacyHost):
+		org = strings.TrimSuffix(strings.ToLower(u.Hostname()), azureLegacyHost)
+		if len(parts) > 0 && strings.EqualFold(parts[0], "DefaultCollection") {
+			parts = parts[1:]
+		}
+	default:
+		return nil, fmt.Errorf("%s is not an Azure Repos url", repo)
+	}
+
+	// {project}/_git/{repo}
+	if len(parts) != 3 || parts[1] != "_git" {
+		return nil, fmt.Errorf("Azure repository url %s must be in the form https://dev.azure.com/{org}/{project}/_git/{repo}", repo)
+	}
+	return newAzureRepo(org, parts[0], parts[2])
+}
+
+// parseAzureSSHRepo parses git@ssh.dev.azure.com:v3/{org}/{project}/{repo}
+// and ssh://git@ssh.dev.azure.com/v3/{org}/{project}/{repo}
+func parseAzureSSHRepo(repo string) (*AzureRepo, error) {
+	var path string
+	if strings.HasPrefix(repo, "ssh://") {
+		u, err := url.Parse(repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Azure repository url %s: %w", repo, err)
+		}
+		path = u.EscapedPath()
+	} else {
+		idx := strings.Index(repo, ":")
+		if idx < 0 {
+			return nil, fmt.Errorf("Azure repository url %s is not a valid SSH url", repo)
+		}
+		path = repo[idx+1:]
+	}
+
+	// v3/{org}/{project}/{repo}
+	parts := splitPath(path)
+	if len(parts) != 4 || parts[0] != "v3" {
+		return nil, fmt.Errorf("Azure repository url %s must be in the form git@ssh.dev.azure.com:v3/{org}/{project}/{repo}", repo)
+	}
+	return newAzureRepo(parts[1], parts[2], parts[3])
+}
+
+func newAzureRepo(org, project, name string) (*AzureRepo, error) {
+	var err error
+	if org, err = url.PathUnescape(org); err != nil {
+		return nil, err
+	}
+	if project, err = url.PathUnescape(project); err != nil {
+		return nil, err
+	}
+	if name, err = url.PathUnescape(name); err != nil {
+		return nil, err
+	}
+	return &AzureRepo{
+		Organization: org,
+		Project:      project,
+		Name:         strings.TrimSuffix(name, ".git"),
+	}, nil
+}
+
+func splitPath(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,33 @@
+package gitclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+type BitbucketServerRepo struct {
+	Project string
+	Name    string
+}
+
+// ParseBitbucketServerRepo extracts the project key and repository slug from
+// a Bitbucket Server clone URL. The HTTP form
+// https://{host}[/{context}]/scm/{project}/{repo}.git and the SSH form
+// ssh://git@{host}:7999/{project}/{repo}.git are supported, personal
+// repositories use ~{user} as the project.
+func ParseBitbucketServerRepo(repo string) (*BitbucketServerRepo, error) {
+	path, err := repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	// the project and repository are always the last two path segments,
+	// after the optional context path and the scm prefix of http urls
+	parts := splitPath(strings.TrimSuffix(path, ".git"))
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("Bitbucket Server repository url %s must be in the form https://{host}/scm/{project}/{repo}.git", repo)
+	}
+	return &BitbucketServerRepo{
+		Project: parts[len(parts)-2],
+		Name:    parts[len(parts)-1],
+	}, nil
+}
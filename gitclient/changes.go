@@ -0,0 +1,98 @@
+package gitclient
+
+import (
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+)
+
+// GithubFile is a file changed by a commit, comparison or pull request
+// as reported by the Github API.
+type GithubFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+}
+
+// GithubChangedFiles converts the changed files reported by Github.
+func GithubChangedFiles(from []*GithubFile) []common.ChangedFile {
+	files := []common.ChangedFile{}
+	for _, f := range from {
+		file := common.ChangedFile{
+			Path:      f.Filename,
+			Status:    common.FileModified,
+			Additions: f.Additions,
+			Deletions: f.Deletions,
+		}
+		switch f.Status {
+		case "added", "copied":
+			file.Status = common.FileAdded
+		case "removed":
+			file.Status = common.FileDeleted
+		case "renamed":
+			file.Status = common.FileRenamed
+			file.PreviousPath = f.PreviousFilename
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// GitlabDiff is a file changed by a commit, comparison or merge request
+// as reported by the Gitlab API.
+type GitlabDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+	Diff        string `json:"diff"`
+}
+
+// GitlabChangedFiles converts the diffs reported by Gitlab. Gitlab does
+// not report line stats per file, they are counted from the diff instead.
+func GitlabChangedFiles(from []*GitlabDiff) []common.ChangedFile {
+	files := []common.ChangedFile{}
+	for _, d := range from {
+		file := common.ChangedFile{Path: d.NewPath, Status: common.FileModified}
+		switch {
+		case d.NewFile:
+			file.Status = common.FileAdded
+		case d.DeletedFile:
+			file.Status = common.FileDeleted
+			file.Path = d.OldPath
+		case d.RenamedFile:
+			file.Status = common.FileRenamed
+			file.PreviousPath = d.OldPath
+		}
+		// the diff only holds hunks, without the ---/+++ file headers
+		for _, line := range strings.Split(d.Diff, "\n") {
+			if strings.HasPrefix(line, "+") {
+				file.Additions++
+			} else if strings.HasPrefix(line, "-") {
+				file.Deletions++
+			}
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// ScmChangedFile converts a change reported by the generic API, which
+// carries no line stats.
+func ScmChangedFile(from *scm.Change) common.ChangedFile {
+	file := common.ChangedFile{Path: from.Path, Status: common.FileModified}
+	switch {
+	case from.Added:
+		file.Status = common.FileAdded
+	case from.Deleted:
+		file.Status = common.FileDeleted
+	case from.Renamed:
+		file.Status = common.FileRenamed
+		file.PreviousPath = from.PrevFilePath
+	}
+	return file
+}
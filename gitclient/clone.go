@@ -0,0 +1,176 @@
+package gitclient
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// Clone clones the repository into memory, without a worktree, and returns
+// the commit the ref resolves to. Branches and tags are fetched on their own
+// and shallowly when depth is positive, while any other ref such as a commit
+// SHA needs the full history. An empty ref resolves to the default branch.
+func (gc *GitClient) Clone(ref string, depth int) (*git.Repository, *object.Commit, error) {
+	url, auth, err := gc.remote()
+	if err != nil {
+		logrus.Error(err.Error())
+		return nil, nil, err
+	}
+	options := &git.CloneOptions{
+		URL:        url,
+		Auth:       auth,
+		NoCheckout: true,
+		Tags:       git.NoTags,
+	}
+
+	var refName plumbing.ReferenceName
+	if ref != "" {
+		refs, err := gc.ListRefs()
+		if err != nil {
+			return nil, nil, err
+		}
+		refName = FindRef(refs, ref)
+	}
+	if ref == "" || refName != "" {
+		options.ReferenceName = refName
+		options.SingleBranch = true
+		options.Depth = depth
+	} else {
+		logrus.Infof("Ref %s is not a branch or tag, cloning the full history", ref)
+		options.Tags = git.AllTags
+	}
+
+	logrus.Infof("Cloning repository at ref %s", ref)
+	repo, err := git.Clone(memory.NewStorage(), nil, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var hash *plumbing.Hash
+	switch {
+	case ref == "":
+		head, err := repo.Head()
+		if err != nil {
+			return nil, nil, err
+		}
+		h := head.Hash()
+		hash = &h
+	case refName != "":
+		hash, err = repo.ResolveRevision(plumbing.Revision(refName))
+	default:
+		hash, err = repo.ResolveRevision(plumbing.Revision(ref))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find commit for ref %s: %w", ref, err)
+	}
+	return repo, commit, nil
+}
+
+// FetchRefs fetches the full history of the given refs into a single
+// repository in memory and returns the commit each ref resolves to. Refs that
+// are not branches or tags, such as commit SHAs, need every branch and tag to
+// be fetched. An empty ref resolves to the default branch.
+func (gc *GitClient) FetchRefs(refs ...string) (*git.Repository, []*object.Commit, error) {
+	url, auth, err := gc.remote()
+	if err != nil {
+		logrus.Error(err.Error())
+		return nil, nil, err
+	}
+	advertised, err := gc.ListRefs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]plumbing.ReferenceName, len(refs))
+	specs := []config.RefSpec{}
+	fetchAll := false
+	for i, ref := range refs {
+		if ref == "" {
+			names[i] = HeadTarget(advertised)
+		} else {
+			names[i] = FindRef(advertised, ref)
+		}
+		if names[i] == "" {
+			fetchAll = true
+			continue
+		}
+		specs = append(specs, config.RefSpec(fmt.Sprintf("+%s:%s", names[i], names[i])))
+	}
+	if fetchAll {
+		logrus.Info("Not every ref is a branch or tag, fetching all of them")
+		specs = []config.RefSpec{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"}
+	}
+
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	remote, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url}})
+	if err != nil {
+		return nil, nil, err
+	}
+	logrus.Infof("Fetching refs %v", refs)
+	err = remote.Fetch(&git.FetchOptions{
+		Auth:     auth,
+		RefSpecs: specs,
+		Tags:     git.NoTags,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, nil, err
+	}
+
+	commits := make([]*object.Commit, len(refs))
+	for i, ref := range refs {
+		revision := plumbing.Revision(ref)
+		if names[i] != "" {
+			revision = plumbing.Revision(names[i])
+		}
+		hash, err := repo.ResolveRevision(revision)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+		}
+		if commits[i], err = repo.CommitObject(*hash); err != nil {
+			return nil, nil, fmt.Errorf("failed to find commit for ref %s: %w", ref, err)
+		}
+	}
+	return repo, commits, nil
+}
+
+// HeadTarget returns the branch the HEAD of the remote points to, or an
+// empty name when the remote does not advertise it.
+func HeadTarget(refs []*plumbing.Reference) plumbing.ReferenceName {
+	for _, r := range refs {
+		if r.Name() == plumbing.HEAD && r.Type() == plumbing.SymbolicReference {
+			return r.Target()
+		}
+	}
+	return ""
+}
+
+// FindRef returns the full name of the advertised reference matching the
+// given branch, tag or full reference name, or an empty name when there is
+// no match. Like git, tags take precedence over branches of the same name.
+func FindRef(refs []*plumbing.Reference, ref string) plumbing.ReferenceName {
+	candidates := []plumbing.ReferenceName{
+		plumbing.ReferenceName(ref),
+		plumbing.NewTagReferenceName(ref),
+		plumbing.NewBranchReferenceName(ref),
+	}
+	for _, candidate := range candidates {
+		for _, r := range refs {
+			if r.Name() == candidate && r.Name() != plumbing.HEAD {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
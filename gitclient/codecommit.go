@@ -0,0 +1,116 @@
+package gitclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/harness/git-connector-cgi/common"
+)
+
+const (
+	codeCommitScheme  = "codecommit"
+	codeCommitService = "codecommit"
+	codeCommitMethod  = "GIT"
+	sigV4Algorithm    = "AWS4-HMAC-SHA256"
+	sigV4Request      = "aws4_request"
+	sigV4TimeFormat   = "20060102T150405"
+	sigV4DateFormat   = "20060102"
+)
+
+// IsCodeCommitURL returns true for codecommit://repo and
+// codecommit::region://repo style repository URLs.
+func IsCodeCommitURL(repo string) bool {
+	return strings.HasPrefix(repo, codeCommitScheme+":")
+}
+
+// ResolveCodeCommitURL converts a codecommit::region://repo url into the
+// https://git-codecommit.region.amazonaws.com/v1/repos/repo url used by git.
+// Any other url is returned as is. The region of the url takes precedence over
+// the region of the credentials.
+func ResolveCodeCommitURL(repo string, region string) (string, error) {
+	if !IsCodeCommitURL(repo) {
+		return repo, nil
+	}
+	rest := strings.TrimPrefix(repo, codeCommitScheme+":")
+	if strings.HasPrefix(rest, ":") {
+		// codecommit::region://repo
+		idx := strings.Index(rest, "://")
+		if idx < 0 {
+			return "", fmt.Errorf("CodeCommit repository url %s is not valid", repo)
+		}
+		region = rest[1:idx]
+		rest = rest[idx+3:]
+	} else {
+		// codecommit://repo
+		rest = strings.TrimPrefix(rest, "//")
+	}
+	// an optional aws profile may prefix the repository name
+	if idx := strings.LastIndex(rest, "@"); idx >= 0 {
+		rest = rest[idx+1:]
+	}
+	if rest == "" {
+		return "", fmt.Errorf("CodeCommit repository url %s is missing the repository name", repo)
+	}
+	if region == "" {
+		return "", fmt.Errorf("CodeCommit region is missing for repository %s", repo)
+	}
+	return fmt.Sprintf("https://git-codecommit.%s.amazonaws.com/v1/repos/%s", region, rest), nil
+}
+
+// codeCommitRegion returns the region of a git-codecommit.region.amazonaws.com
+// host, or an empty string for any other host.
+func codeCommitRegion(host string) string {
+	parts := strings.Split(host, ".")
+	if len(parts) == 4 && strings.HasPrefix(parts[0], "git-codecommit") && parts[2] == "amazonaws" {
+		return parts[1]
+	}
+	return ""
+}
+
+// GetCodeCommitCredentials computes the git username and the SigV4 signed
+// password for a CodeCommit https repository url, the same way the AWS CLI
+// credential helper does.
+func GetCodeCommitCredentials(repo string, config *common.AWSCredentials, now time.Time) (string, string, error) {
+	u, err := url.Parse(repo)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse CodeCommit repository url %s: %w", repo, err)
+	}
+	region := config.Region
+	if hostRegion := codeCommitRegion(u.Hostname()); hostRegion != "" {
+		region = hostRegion
+	}
+	if region == "" {
+		return "", "", fmt.Errorf("CodeCommit region is missing for repository %s", repo)
+	}
+
+	timestamp := now.UTC().Format(sigV4TimeFormat)
+	date := now.UTC().Format(sigV4DateFormat)
+	scope := fmt.Sprintf("%s/%s/%s/%s", date, region, codeCommitService, sigV4Request)
+
+	canonicalRequest := fmt.Sprintf("%s\n%s\n\nhost:%s\n\nhost\n", codeCommitMethod, u.EscapedPath(), u.Hostname())
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s", sigV4Algorithm, timestamp, scope, hex.EncodeToString(hash[:]))
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, codeCommitService)
+	key = hmacSHA256(key, sigV4Request)
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	username := config.AccessKeyId
+	if config.SessionToken != "" {
+		username = fmt.Sprintf("%s%%%s", config.AccessKeyId, config.SessionToken)
+	}
+	return username, fmt.Sprintf("%sZ%s", timestamp, signature), nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
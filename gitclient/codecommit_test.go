@@ -0,0 +1,119 @@
+package gitclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harness/git-connector-cgi/common"
+)
+
+func TestGetCodeCommitCredentials(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	// expected passwords are computed as the AWS CLI credential helper does
+	tests := []struct {
+		name     string
+		repo     string
+		config   common.AWSCredentials
+		username string
+		password string
+	}{
+		{
+			name:     "region from host",
+			repo:     "https://git-codecommit.us-east-1.amazonaws.com/v1/repos/my-repo",
+			config:   common.AWSCredentials{AccessKeyId: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", Region: "eu-west-1"},
+			username: "AKIDEXAMPLE",
+			password: "20260102T030405Z4081ba6ea6f21ccb0dd59e023f1e29e74ae0569814d9a70597c26297792a91b5",
+		},
+		{
+			name:     "port is not signed",
+			repo:     "https://git-codecommit.eu-west-1.amazonaws.com:443/v1/repos/my-repo",
+			config:   common.AWSCredentials{AccessKeyId: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"},
+			username: "AKIDEXAMPLE",
+			password: "20260102T030405Zea9f2bc2c4dd10953a2a25a7686841d81fa18d9cf5a6d47315dc67495c389bcd",
+		},
+		{
+			name:     "region from credentials with session token",
+			repo:     "https://codecommit.example.com:8443/v1/repos/my-repo",
+			config:   common.AWSCredentials{AccessKeyId: "ASIAEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", SessionToken: "token", Region: "ap-south-1"},
+			username: "ASIAEXAMPLE%token",
+			password: "20260102T030405Z337742a376765bfb872d2762d4a5f0acfb4a802dae1e1a21cbba24d7ec5bc41d",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			username, password, err := GetCodeCommitCredentials(test.repo, &test.config, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if username != test.username {
+				t.Errorf("want username %s, got %s", test.username, username)
+			}
+			if password != test.password {
+				t.Errorf("want password %s, got %s", test.password, password)
+			}
+		})
+	}
+}
+
+func TestGetCodeCommitCredentialsMissingRegion(t *testing.T) {
+	_, _, err := GetCodeCommitCredentials("https://codecommit.example.com/v1/repos/my-repo", &common.AWSCredentials{}, time.Now())
+	if err == nil {
+		t.Error("want an error for a missing region")
+	}
+}
+
+func TestListRefsCodeCommitCredentials(t *testing.T) {
+	config := &common.AWSCredentials{AccessKeyId: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", Region: "us-east-1"}
+	var repo string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// the password starts with the time it was signed at
+		signedAt, err := time.Parse(sigV4TimeFormat, strings.SplitN(password, "Z", 2)[0])
+		if err != nil {
+			t.Errorf("want a signed password, got %s", password)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		wantUsername, wantPassword, err := GetCodeCommitCredentials(repo, config, signedAt)
+		if err != nil || username != wantUsername || password != wantPassword {
+			t.Errorf("want credentials %s:%s, got %s:%s", wantUsername, wantPassword, username, password)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		for _, line := range []string{
+			"# service=git-upload-pack\n",
+			"",
+			"0123456789012345678901234567890123456789 refs/heads/main\x00symref=HEAD:refs/heads/main\n",
+			"",
+		} {
+			if line == "" {
+				fmt.Fprint(w, "0000")
+				continue
+			}
+			fmt.Fprintf(w, "%04x%s", len(line)+4, line)
+		}
+	}))
+	defer server.Close()
+
+	repo = server.URL + "/v1/repos/my-repo"
+	gc := NewHttp(repo, &common.HTTPAuth{AuthMethod: common.HTTPAuthAWSCredentials, AWS: config})
+	refs, err := gc.ListRefs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range refs {
+		if ref.Name() == "refs/heads/main" {
+			return
+		}
+	}
+	t.Errorf("want refs/heads/main, got %v", refs)
+}
@@ -0,0 +1,139 @@
+package gitclient
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/sirupsen/logrus"
+)
+
+// FileChange is a change to a single file of a commit. Without an action
+// the file is written whether or not it exists.
+type FileChange struct {
+	Action  common.FileAction
+	Path    string
+	Content []byte
+	// BlobSha is the SHA of the blob the file is expected to have before
+	// the change. When set, the commit fails if the file has changed.
+	BlobSha string
+}
+
+// CommitFiles commits the changes on top of the branch and pushes the commit.
+// The branch is cloned shallowly into memory, along with its worktree. The
+// push is not forced, so it fails when the branch moved in the meantime.
+func (gc *GitClient) CommitFiles(branch, message string, author *object.Signature, changes []FileChange) (*object.Commit, error) {
+	repo, err := gc.cloneBranch(branch)
+	if err != nil {
+		return nil, err
+	}
+	return gc.commitAndPush(repo, branch, message, author, changes)
+}
+
+// cloneBranch clones the branch shallowly into memory, along with its
+// worktree.
+func (gc *GitClient) cloneBranch(branch string) (*git.Repository, error) {
+	url, auth, err := gc.remote()
+	if err != nil {
+		logrus.Error(err.Error())
+		return nil, err
+	}
+	logrus.Infof("Cloning branch %s", branch)
+	return git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{
+		URL:           url,
+		Auth:          auth,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+		Depth:         1,
+		Tags:          git.NoTags,
+	})
+}
+
+// commitAndPush commits the changes on top of the head of the cloned branch
+// and pushes the commit to the branch.
+func (gc *GitClient) commitAndPush(repo *git.Repository, branch, message string, author *object.Signature, changes []FileChange) (*object.Commit, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	parent, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := parent.Tree()
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		if err := checkBlob(tree, change); err != nil {
+			return nil, err
+		}
+		if change.Action == common.FileDelete {
+			if _, err := worktree.Remove(change.Path); err != nil {
+				return nil, fmt.Errorf("failed to delete file %s: %w", change.Path, err)
+			}
+			continue
+		}
+		if err := worktree.Filesystem.MkdirAll(path.Dir(change.Path), 0755); err != nil {
+			return nil, err
+		}
+		if err := util.WriteFile(worktree.Filesystem, change.Path, change.Content, 0644); err != nil {
+			return nil, err
+		}
+		if _, err := worktree.Add(change.Path); err != nil {
+			return nil, fmt.Errorf("failed to add file %s: %w", change.Path, err)
+		}
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: author})
+	if err != nil {
+		if errors.Is(err, git.ErrEmptyCommit) {
+			return nil, errors.New("the changes leave the files as they are")
+		}
+		return nil, err
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	refName := plumbing.NewBranchReferenceName(branch)
+	logrus.Infof("Pushing commit %s to branch %s", hash, branch)
+	if err := gc.push(repo, config.RefSpec(fmt.Sprintf("%s:%s", refName, refName))); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// checkBlob verifies the file exists as the change expects, with the
+// expected blob.
+func checkBlob(tree *object.Tree, change FileChange) error {
+	file, err := tree.File(change.Path)
+	switch {
+	case errors.Is(err, object.ErrFileNotFound):
+		if change.BlobSha != "" || change.Action == common.FileUpdate || change.Action == common.FileDelete {
+			return fmt.Errorf("file %s not found", change.Path)
+		}
+		return nil
+	case err != nil:
+		return err
+	case change.Action == common.FileCreate:
+		return fmt.Errorf("file %s already exists", change.Path)
+	}
+	if change.BlobSha != "" && file.Hash.String() != change.BlobSha {
+		return fmt.Errorf("file %s has changed, its blob is %s instead of %s", change.Path, file.Hash, change.BlobSha)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package gitclient
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/harness/git-connector-cgi/common"
+)
+
+var testAuthor = &object.Signature{Name: "Test", Email: "test@example.com", When: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+// newBareRepo creates a bare repository with a main branch holding a README
+// and returns its directory.
+func newBareRepo(t *testing.T) string {
+	// the file transport runs git-upload-pack and git-receive-pack
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := util.WriteFile(worktree.Filesystem, "README.md", []byte("readme"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("README.md"); err != nil {
+		t.Fatal(err)
+	}
+	head, err := worktree.Commit("Initial commit", &git.CommitOptions{Author: testAuthor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := repo.CreateRemoteAnonymous(&config.RemoteConfig{Name: "anonymous", URLs: []string{"file://" + dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = remote.Push(&git.PushOptions{
+		RemoteName: "anonymous",
+		RefSpecs:   []config.RefSpec{config.RefSpec(head.String() + ":refs/heads/main")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// readFiles returns the files on the branch of the bare repository.
+func readFiles(t *testing.T, dir, branch string) map[string]string {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := commit.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string]string{}
+	err = files.ForEach(func(file *object.File) error {
+		content, err := file.Contents()
+		contents[file.Name] = content
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contents
+}
+
+func blobSha(content string) string {
+	return plumbing.ComputeHash(plumbing.BlobObject, []byte(content)).String()
+}
+
+func TestCommitFiles(t *testing.T) {
+	dir := newBareRepo(t)
+	gc := NewHttp("file://"+dir, &common.HTTPAuth{AuthMethod: common.HTTPAuthAnonymous})
+
+	tests := []struct {
+		name    string
+		changes []FileChange
+		want    map[string]string
+	}{
+		{
+			name: "create",
+			changes: []FileChange{
+				{Action: common.FileCreate, Path: "pipelines/build.yaml", Content: []byte("build")},
+			},
+			want: map[string]string{"README.md": "readme", "pipelines/build.yaml": "build"},
+		},
+		{
+			name: "update",
+			changes: []FileChange{
+				{Action: common.FileUpdate, Path: "README.md", Content: []byte("docs"), BlobSha: blobSha("readme")},
+				{Action: common.FileUpdate, Path: "pipelines/build.yaml", Content: []byte("deploy")},
+			},
+			want: map[string]string{"README.md": "docs", "pipelines/build.yaml": "deploy"},
+		},
+		{
+			name: "delete",
+			changes: []FileChange{
+				{Action: common.FileDelete, Path: "pipelines/build.yaml", BlobSha: blobSha("deploy")},
+			},
+			want: map[string]string{"README.md": "docs"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			commit, err := gc.CommitFiles("main", "Commit "+test.name, testAuthor, test.changes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if commit.Message != "Commit "+test.name {
+				t.Errorf("want message Commit %s, got %s", test.name, commit.Message)
+			}
+			files := readFiles(t, dir, "main")
+			if len(files) != len(test.want) {
+				t.Errorf("want files %v, got %v", test.want, files)
+			}
+			for path, content := range test.want {
+				if files[path] != content {
+					t.Errorf("want %s to hold %q, got %q", path, content, files[path])
+				}
+			}
+		})
+	}
+}
+
+func TestCommitFilesBlobMismatch(t *testing.T) {
+	dir := newBareRepo(t)
+	gc := NewHttp("file://"+dir, &common.HTTPAuth{AuthMethod: common.HTTPAuthAnonymous})
+
+	changes := []FileChange{
+		{Action: common.FileUpdate, Path: "README.md", Content: []byte("docs"), BlobSha: blobSha("stale")},
+	}
+	_, err := gc.CommitFiles("main", "Update readme", testAuthor, changes)
+	if err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("want a changed file error, got %v", err)
+	}
+	if files := readFiles(t, dir, "main"); files["README.md"] != "readme" {
+		t.Errorf("want the readme unchanged, got %q", files["README.md"])
+	}
+}
+
+func TestCommitFilesNonFastForward(t *testing.T) {
+	dir := newBareRepo(t)
+	gc := NewHttp("file://"+dir, &common.HTTPAuth{AuthMethod: common.HTTPAuthAnonymous})
+
+	// the branch moves on between the clone and the push
+	repo, err := gc.cloneBranch("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := gc.CommitFiles("main", "Add build", testAuthor, []FileChange{
+		{Action: common.FileCreate, Path: "build.yaml", Content: []byte("build")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = gc.commitAndPush(repo, "main", "Add deploy", testAuthor, []FileChange{
+		{Action: common.FileCreate, Path: "deploy.yaml", Content: []byte("deploy")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "non-fast-forward") {
+		t.Fatalf("want a rejected non-fast-forward push, got %v", err)
+	}
+
+	bare, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := bare.Reference(plumbing.NewBranchReferenceName("main"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Hash() != moved.Hash {
+		t.Errorf("want main at %s, got %s", moved.Hash, ref.Hash())
+	}
+}
@@ -0,0 +1,166 @@
+package gitclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/sirupsen/logrus"
+)
+
+// probeTimeout bounds probing a host for all the providers together.
+const probeTimeout = 10 * time.Second
+
+// DetectProvider infers the git provider and its API endpoint from the
+// repository URL. Well known SaaS hosts are matched by name, other hosts are
+// probed for the API endpoints of the self hosted providers. An empty provider
+// is returned when nothing could be detected.
+func DetectProvider(repo string, proxy string) (common.Provider, string) {
+	if IsCodeCommitURL(repo) {
+		return common.CodeCommit, ""
+	}
+	scheme, host := repoHost(repo)
+	if host == "" {
+		return "", ""
+	}
+	host = strings.ToLower(host)
+	hostname := host
+	if idx := strings.LastIndex(hostname, ":"); idx >= 0 && !strings.HasSuffix(hostname, "]") {
+		hostname = hostname[:idx]
+	}
+
+	switch {
+	case hostname == "github.com" || hostname == "ssh.github.com":
+		return common.Github, "https://api.github.com"
+	case hostname == "gitlab.com":
+		return common.GitLab, "https://gitlab.com"
+	case hostname == "bitbucket.org":
+		return common.Bitbucket, "https://api.bitbucket.org"
+	case hostname == azureHost || hostname == "ssh."+azureHost || strings.HasSuffix(hostname, azureLegacyHost):
+		return common.AzureRepo, azureEndpoint
+	case codeCommitRegion(hostname) != "":
+		return common.CodeCommit, ""
+	case hostname == "git.harness.io" || hostname == "app.harness.io":
+		return common.Harness, harnessEndpoint
+	case strings.HasPrefix(hostname, "github."):
+		// Github Enterprise Server serves its API under /api/v3
+		return common.Github, fmt.Sprintf("https://%s/api/v3", host)
+	}
+	return probeProvider(scheme, host, proxy)
+}
+
+// repoHost returns the scheme and host of an http(s), ssh or scp-like
+// git URL. The scheme of ssh URLs is reported as https since the API of
+// self hosted providers is served over https, the ssh port is dropped
+// for the same reason while an explicit http(s) port is kept.
+func repoHost(repo string) (string, string) {
+	if strings.Contains(repo, "://") {
+		u, err := url.Parse(repo)
+		if err != nil {
+			return "", ""
+		}
+		if u.Scheme == "http" || u.Scheme == "https" {
+			return u.Scheme, u.Host
+		}
+		return "https", u.Hostname()
+	}
+	// scp-like syntax, [user@]host:path
+	idx := strings.Index(repo, ":")
+	if idx < 0 {
+		return "", ""
+	}
+	host := repo[:idx]
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	return "https", host
+}
+
+type providerProbe struct {
+	provider common.Provider
+	path     string
+	endpoint string
+	match    func(res *http.Response, body []byte) bool
+}
+
+var providerProbes = []providerProbe{
+	{
+		provider: common.Github,
+		path:     "/api/v3/meta",
+		endpoint: "/api/v3",
+		match: func(res *http.Response, _ []byte) bool {
+			return res.Header.Get("X-GitHub-Enterprise-Version") != ""
+		},
+	},
+	{
+		provider: common.GitLab,
+		path:     "/api/v4/version",
+		match: func(res *http.Response, _ []byte) bool {
+			return res.Header.Get("X-Gitlab-Meta") != ""
+		},
+	},
+	{
+		provider: common.BitbucketServer,
+		path:     "/rest/api/1.0/application-properties",
+		match: func(res *http.Response, body []byte) bool {
+			return res.StatusCode == http.StatusOK && bytes.Contains(body, []byte(`"displayName":"Bitbucket"`))
+		},
+	},
+	{
+		provider: common.Forgejo,
+		path:     "/api/forgejo/v1/version",
+		match: func(res *http.Response, body []byte) bool {
+			return res.StatusCode == http.StatusOK && bytes.Contains(body, []byte(`"version"`))
+		},
+	},
+	{
+		provider: common.Gitea,
+		path:     "/api/v1/version",
+		match: func(res *http.Response, body []byte) bool {
+			return res.StatusCode == http.StatusOK && bytes.Contains(body, []byte(`"version"`))
+		},
+	},
+	{
+		provider: common.Gerrit,
+		path:     "/config/server/version",
+		match: func(res *http.Response, body []byte) bool {
+			return res.StatusCode == http.StatusOK && bytes.HasPrefix(body, []byte(")]}'"))
+		},
+	},
+}
+
+// probeProvider calls the well known, unauthenticated API endpoints of the
+// self hosted providers and returns the first one that answers.
+func probeProvider(scheme, host, proxy string) (common.Provider, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	client := &http.Client{
+		Transport: defaultTransport(SkipSSLVerify, AdditionalCertsPath, proxy),
+	}
+	base := fmt.Sprintf("%s://%s", scheme, host)
+	for _, probe := range providerProbes {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+probe.path, nil)
+		if err != nil {
+			return "", ""
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			logrus.Warnf("Failed to probe %s for provider %s: %v", host, probe.provider, err)
+			// the host is most likely unreachable over http
+			return "", ""
+		}
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		res.Body.Close()
+		if probe.match(res, body) {
+			logrus.Infof("Detected provider %s for host %s", probe.provider, host)
+			return probe.provider, base + probe.endpoint
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,54 @@
+package gitclient
+
+import (
+	"context"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/harness/git-connector-cgi/common"
+)
+
+// DiffTrees returns the files changed between two trees along with their
+// line stats. Renames are detected the same way git diff does by default.
+// A nil from tree stands for the empty tree of a root commit.
+func DiffTrees(ctx context.Context, from, to *object.Tree) ([]common.ChangedFile, error) {
+	if from == nil {
+		from = &object.Tree{}
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []common.ChangedFile{}
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+		file := common.ChangedFile{Path: change.To.Name}
+		switch {
+		case action == merkletrie.Insert:
+			file.Status = common.FileAdded
+		case action == merkletrie.Delete:
+			file.Status = common.FileDeleted
+			file.Path = change.From.Name
+		case change.From.Name != change.To.Name:
+			file.Status = common.FileRenamed
+			file.PreviousPath = change.From.Name
+		default:
+			file.Status = common.FileModified
+		}
+
+		patch, err := change.PatchContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range patch.Stats() {
+			file.Additions += stat.Addition
+			file.Deletions += stat.Deletion
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
@@ -0,0 +1,185 @@
+package gitclient
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ChallengeAuth is an http.RoundTripper that answers the authentication
+// challenge returned by the server, using HTTP digest or basic auth depending
+// on what the server asks for. It is used for servers such as Gerrit which may
+// be configured for either scheme.
+type ChallengeAuth struct {
+	Base http.RoundTripper
+
+	Username string
+	Password string
+}
+
+// RoundTrip sends the request without credentials first and retries it once
+// with the credentials matching the WWW-Authenticate challenge.
+func (t *ChallengeAuth) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := t.base().RoundTrip(cloneRequest(r))
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	challenge := res.Header.Get("WWW-Authenticate")
+	if challenge == "" {
+		return res, nil
+	}
+
+	r2 := cloneRequest(r)
+	if r.Body != nil {
+		if r.GetBody == nil {
+			// the body was consumed by the first attempt
+			return res, nil
+		}
+		if r2.Body, err = r.GetBody(); err != nil {
+			return res, nil
+		}
+	}
+	switch {
+	case strings.HasPrefix(strings.ToLower(challenge), "digest "):
+		authorization, err := t.digestAuthorization(r2, challenge)
+		if err != nil {
+			return res, nil
+		}
+		r2.Header.Set("Authorization", authorization)
+	case strings.HasPrefix(strings.ToLower(challenge), "basic"):
+		r2.SetBasicAuth(t.Username, t.Password)
+	default:
+		return res, nil
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	return t.base().RoundTrip(r2)
+}
+
+// digestAuthorization builds the Authorization header value for a digest
+// challenge as described in RFC 7616.
+func (t *ChallengeAuth) digestAuthorization(r *http.Request, challenge string) (string, error) {
+	params := parseChallenge(challenge[len("digest "):])
+	realm, nonce := params["realm"], params["nonce"]
+	if nonce == "" {
+		return "", fmt.Errorf("digest challenge is missing the nonce")
+	}
+
+	var h func() hash.Hash
+	algorithm := params["algorithm"]
+	switch strings.ToUpper(algorithm) {
+	case "", "MD5":
+		h = md5.New
+	case "SHA-256":
+		h = sha256.New
+	default:
+		return "", fmt.Errorf("digest algorithm %s is not supported", algorithm)
+	}
+	digest := func(s string) string {
+		d := h()
+		io.WriteString(d, s)
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	uri := r.URL.RequestURI()
+	ha1 := digest(fmt.Sprintf("%s:%s:%s", t.Username, realm, t.Password))
+	ha2 := digest(fmt.Sprintf("%s:%s", r.Method, uri))
+
+	authorization := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s"`, t.Username, realm, nonce, uri)
+	if qop := params["qop"]; qop != "" {
+		// only the auth quality of protection is supported
+		if !containsToken(qop, "auth") {
+			return "", fmt.Errorf("digest qop %s is not supported", qop)
+		}
+		cnonce, err := newCnonce()
+		if err != nil {
+			return "", err
+		}
+		nc := "00000001"
+		response := digest(fmt.Sprintf("%s:%s:%s:%s:auth:%s", ha1, nonce, nc, cnonce, ha2))
+		authorization += fmt.Sprintf(`, qop=auth, nc=%s, cnonce="%s", response="%s"`, nc, cnonce, response)
+	} else {
+		response := digest(fmt.Sprintf("%s:%s:%s", ha1, nonce, ha2))
+		authorization += fmt.Sprintf(`, response="%s"`, response)
+	}
+	if algorithm != "" {
+		authorization += fmt.Sprintf(", algorithm=%s", algorithm)
+	}
+	if opaque := params["opaque"]; opaque != "" {
+		authorization += fmt.Sprintf(`, opaque="%s"`, opaque)
+	}
+	return authorization, nil
+}
+
+func (t *ChallengeAuth) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// parseChallenge parses the comma separated key=value pairs of a
+// WWW-Authenticate challenge.
+func parseChallenge(s string) map[string]string {
+	params := map[string]string{}
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else if end := strings.Index(s, ","); end >= 0 {
+			value, s = s[:end], s[end:]
+		} else {
+			value, s = s, ""
+		}
+		params[key] = strings.TrimSpace(value)
+	}
+	return params
+}
+
+func containsToken(list, token string) bool {
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == token {
+			return true
+		}
+	}
+	return false
+}
+
+func newCnonce() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// cloneRequest returns a shallow copy of the request with a deep copy of the
+// headers, so the original request is never modified.
+func cloneRequest(r *http.Request) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.Header = make(http.Header, len(r.Header))
+	for k, s := range r.Header {
+		r2.Header[k] = append([]string(nil), s...)
+	}
+	return r2
+}
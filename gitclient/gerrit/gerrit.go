@@ -0,0 +1,101 @@
+// Package gerrit implements the subset of the go-scm client services
+// needed by the connector on top of the Gerrit REST API.
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+)
+
+// xssiPrefix is prepended by Gerrit to every JSON response to
+// prevent cross site script inclusion.
+var xssiPrefix = []byte(")]}'")
+
+// New returns a new Gerrit API client.
+func New(uri string) (*scm.Client, error) {
+	base, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path = base.Path + "/"
+	}
+	client := &wrapper{new(scm.Client)}
+	client.BaseURL = base
+	// initialize services
+	client.Driver = scm.DriverUnknown
+	client.Repositories = &repositoryService{client}
+	return client.Client, nil
+}
+
+// wrapper wraps the Client to provide high level helper functions
+// for making http requests and unmarshaling the response.
+type wrapper struct {
+	*scm.Client
+}
+
+// do wraps the Client.Do function by creating the Request and
+// unmarshalling the response. Authenticated Gerrit endpoints
+// live under the /a/ prefix, so path should already include it.
+func (c *wrapper) do(ctx context.Context, method, path string, in, out interface{}) (*scm.Response, error) {
+	req := &scm.Request{
+		Method: method,
+		Path:   path,
+		Header: map[string][]string{
+			"Accept": {"application/json"},
+		},
+	}
+	// if we are posting or putting data, we need to
+	// write it to the body of the request.
+	if in != nil {
+		buf := new(bytes.Buffer)
+		json.NewEncoder(buf).Encode(in)
+		req.Header["Content-Type"] = []string{"application/json"}
+		req.Body = buf
+	}
+
+	// execute the http request
+	res, err := c.Client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// if an error is encountered, return the plain text
+	// error message sent by Gerrit.
+	if res.Status > 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		message := strings.TrimSpace(string(body))
+		if message == "" {
+			message = http.StatusText(res.Status)
+		}
+		return res, errors.New(message)
+	}
+
+	if out == nil {
+		return res, nil
+	}
+
+	// if raw output is expected, copy to the provided
+	// buffer and exit.
+	if w, ok := out.(io.Writer); ok {
+		io.Copy(w, res.Body)
+		return res, nil
+	}
+
+	// strip the XSSI prefix before parsing the json response.
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res, err
+	}
+	body = bytes.TrimPrefix(body, xssiPrefix)
+	return res, json.Unmarshal(body, out)
+}
@@ -0,0 +1,145 @@
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+)
+
+type project struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Parent      string `json:"parent"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	More        bool   `json:"_more_projects"`
+}
+
+type repositoryService struct {
+	client *wrapper
+}
+
+// Find returns the Gerrit project with the given name.
+func (s *repositoryService) Find(ctx context.Context, repo string) (*scm.Repository, *scm.Response, error) {
+	path := fmt.Sprintf("a/projects/%s", url.PathEscape(repo))
+	out := new(project)
+	res, err := s.client.do(ctx, "GET", path, nil, out)
+	if err != nil {
+		return nil, res, err
+	}
+	return s.convertProject(out.Name, out), res, nil
+}
+
+func (s *repositoryService) FindHook(ctx context.Context, repo string, id string) (*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) FindPerms(ctx context.Context, repo string) (*scm.Perm, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+// List returns the Gerrit projects visible to the authenticated user.
+func (s *repositoryService) List(ctx context.Context, opts scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return s.list(ctx, "", "", opts)
+}
+
+// ListV2 returns the projects whose name contains the search term.
+func (s *repositoryService) ListV2(ctx context.Context, opts scm.RepoListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return s.list(ctx, "", opts.RepoSearchTerm.RepoName, opts.ListOptions)
+}
+
+// ListNamespace returns the projects below the given namespace.
+func (s *repositoryService) ListNamespace(ctx context.Context, namespace string, opts scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	return s.list(ctx, strings.TrimSuffix(namespace, "/")+"/", "", opts)
+}
+
+func (s *repositoryService) ListHooks(ctx context.Context, repo string, opts scm.ListOptions) ([]*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) ListStatus(ctx context.Context, repo, ref string, opts scm.ListOptions) ([]*scm.Status, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) CreateHook(ctx context.Context, repo string, input *scm.HookInput) (*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) CreateStatus(ctx context.Context, repo, ref string, input *scm.StatusInput) (*scm.Status, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) UpdateHook(ctx context.Context, repo, id string, input *scm.HookInput) (*scm.Hook, *scm.Response, error) {
+	return nil, nil, scm.ErrNotSupported
+}
+
+func (s *repositoryService) DeleteHook(ctx context.Context, repo, id string) (*scm.Response, error) {
+	return nil, scm.ErrNotSupported
+}
+
+// list returns the projects matching the given name prefix. Gerrit
+// paginates with a limit and a number of projects to skip.
+// list lists the projects starting with the prefix or containing the
+// substring, Gerrit accepts only one of them.
+func (s *repositoryService) list(ctx context.Context, prefix, substring string, opts scm.ListOptions) ([]*scm.Repository, *scm.Response, error) {
+	params := url.Values{}
+	if prefix != "" {
+		params.Set("p", prefix)
+	} else if substring != "" {
+		params.Set("m", substring)
+	}
+	if opts.Size != 0 {
+		params.Set("n", strconv.Itoa(opts.Size))
+		if opts.Page > 1 {
+			params.Set("S", strconv.Itoa((opts.Page-1)*opts.Size))
+		}
+	}
+	// d includes the project descriptions in the response
+	path := "a/projects/?d"
+	if len(params) != 0 {
+		path += "&" + params.Encode()
+	}
+	out := map[string]*project{}
+	res, err := s.client.do(ctx, "GET", path, nil, &out)
+	if err != nil {
+		return nil, res, err
+	}
+
+	names := make([]string, 0, len(out))
+	for name := range out {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	repos := []*scm.Repository{}
+	more := false
+	for _, name := range names {
+		repos = append(repos, s.convertProject(name, out[name]))
+		more = more || out[name].More
+	}
+	if more {
+		res.Page.Next = opts.Page + 1
+	}
+	return repos, res, nil
+}
+
+func (s *repositoryService) convertProject(name string, from *project) *scm.Repository {
+	namespace, repoName := "", name
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		namespace, repoName = name[:idx], name[idx+1:]
+	}
+	base := strings.TrimSuffix(s.client.BaseURL.String(), "/")
+	return &scm.Repository{
+		ID:        from.ID,
+		Namespace: namespace,
+		Name:      repoName,
+		Archived:  from.State == "READ_ONLY",
+		Clone:     fmt.Sprintf("%s/%s", base, name),
+		Link:      fmt.Sprintf("%s/admin/repos/%s", base, url.PathEscape(name)),
+	}
+}
@@ -2,6 +2,7 @@ package gitclient
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
@@ -33,20 +34,22 @@ func NewSsh(repo string, config *common.SSHAuth) *GitClient {
 
 func (gc *GitClient) ValidateWithHttp() error {
 	logrus.Info("Validating repository access using HTTP token auth")
+	repo, err := gc.getHttpRepo()
+	if err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
 	remote := git.NewRemote(nil, &config.RemoteConfig{Name: "origin",
-		URLs: []string{gc.Repo},
+		URLs: []string{repo},
 	})
 
-	token, err := gc.getHttpToken()
+	auth, err := gc.getHttpAuth(repo)
 	if err != nil {
 		logrus.Error(err.Error())
 		return err
 	}
 	_, err = remote.List(&git.ListOptions{
-		Auth: &gitHTTP.BasicAuth{
-			Username: gc.HTTPAuth.Username,
-			Password: token,
-		},
+		Auth: auth,
 	})
 	return err
 
@@ -70,6 +73,43 @@ func (gc *GitClient) ValidateWithSSH() error {
 	return err
 }
 
+// getHttpRepo returns the url git should use over HTTP, expanding
+// codecommit:// style urls into their https form.
+func (gc *GitClient) getHttpRepo() (string, error) {
+	if gc.HTTPAuth.AuthMethod != common.HTTPAuthAWSCredentials || gc.HTTPAuth.AWS == nil {
+		return ResolveCodeCommitURL(gc.Repo, "")
+	}
+	return ResolveCodeCommitURL(gc.Repo, gc.HTTPAuth.AWS.Region)
+}
+
+func (gc *GitClient) getHttpAuth(repo string) (*gitHTTP.BasicAuth, error) {
+	if gc.HTTPAuth.AuthMethod == common.HTTPAuthAWSCredentials {
+		if gc.HTTPAuth.AWS == nil {
+			return nil, fmt.Errorf("AWS credentials not provided")
+		}
+		username, password, err := GetCodeCommitCredentials(repo, gc.HTTPAuth.AWS, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return &gitHTTP.BasicAuth{
+			Username: username,
+			Password: password,
+		}, nil
+	}
+	if gc.HTTPAuth.AuthMethod == common.HTTPAuthGithubApp {
+		return gc.getGithubAppAuth()
+	}
+
+	token, err := gc.getHttpToken()
+	if err != nil {
+		return nil, err
+	}
+	return &gitHTTP.BasicAuth{
+		Username: gc.HTTPAuth.Username,
+		Password: token,
+	}, nil
+}
+
 func (gc *GitClient) getHttpToken() (string, error) {
 	if gc.HTTPAuth.AuthMethod == common.HTTPAuthToken {
 		return gc.HTTPAuth.Token, nil
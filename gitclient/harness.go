@@ -0,0 +1,53 @@
+package gitclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const harnessEndpoint = "https://app.harness.io/gateway/code"
+
+type HarnessRepo struct {
+	Account      string
+	Organization string
+	Project      string
+	Name         string
+}
+
+// ParseHarnessRepo extracts the account, organization, project and repository
+// identifiers of a Harness Code repository. The repo may either be a clone URL
+// such as https://git.harness.io/{account}/{org}/{project}/{repo}.git or the
+// scoped identifier {account}/{org}/{project}/{repo}. Organization and project
+// are optional for account and organization level repositories.
+func ParseHarnessRepo(repo string) (*HarnessRepo, error) {
+	path := repo
+	if strings.Contains(repo, "://") {
+		u, err := url.Parse(repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Harness repository url %s: %w", repo, err)
+		}
+		path = u.Path
+	}
+
+	parts := splitPath(strings.TrimSuffix(path, ".git"))
+	// self managed installations serve git under a /git or /code/git prefix
+	for len(parts) > 0 && (parts[0] == "code" || parts[0] == "git") {
+		parts = parts[1:]
+	}
+	if len(parts) < 2 || len(parts) > 4 {
+		return nil, fmt.Errorf("Harness repository %s must be in the form {account}/{org}/{project}/{repo}", repo)
+	}
+
+	harnessRepo := &HarnessRepo{
+		Account: parts[0],
+		Name:    parts[len(parts)-1],
+	}
+	if len(parts) > 2 {
+		harnessRepo.Organization = parts[1]
+	}
+	if len(parts) > 3 {
+		harnessRepo.Project = parts[2]
+	}
+	return harnessRepo, nil
+}
@@ -8,15 +8,23 @@ package gitclient
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 
 	"github.com/drone/go-scm/scm"
+	"github.com/drone/go-scm/scm/driver/azure"
+	"github.com/drone/go-scm/scm/driver/bitbucket"
+	"github.com/drone/go-scm/scm/driver/gitea"
 	"github.com/drone/go-scm/scm/driver/github"
+	"github.com/drone/go-scm/scm/driver/gitlab"
+	"github.com/drone/go-scm/scm/driver/harness"
+	"github.com/drone/go-scm/scm/driver/stash"
 	"github.com/drone/go-scm/scm/transport"
 	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient/gerrit"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -46,6 +54,34 @@ func privateTokenTransport(token string, skip bool, additionalCertsPath string,
 		Token: token,
 	}
 }
+func bearerTokenTransport(token string, skip bool, additionalCertsPath string, proxy string) http.RoundTripper {
+	return &transport.BearerToken{
+		Base:  defaultTransport(skip, additionalCertsPath, proxy),
+		Token: token,
+	}
+}
+func basicAuthTransport(username, password string, skip bool, additionalCertsPath string, proxy string) http.RoundTripper {
+	return &transport.BasicAuth{
+		Base:     defaultTransport(skip, additionalCertsPath, proxy),
+		Username: username,
+		Password: password,
+	}
+}
+func apiKeyTransport(token string, skip bool, additionalCertsPath string, proxy string) http.RoundTripper {
+	return &transport.Custom{
+		Base: defaultTransport(skip, additionalCertsPath, proxy),
+		Before: func(r *http.Request) {
+			r.Header.Set("x-api-key", token)
+		},
+	}
+}
+func challengeAuthTransport(username, password string, skip bool, additionalCertsPath string, proxy string) http.RoundTripper {
+	return &ChallengeAuth{
+		Base:     defaultTransport(skip, additionalCertsPath, proxy),
+		Username: username,
+		Password: password,
+	}
+}
 
 func tlsConfig(skip bool, additionalCertsPath string) *tls.Config {
 	config := tls.Config{
@@ -88,14 +124,21 @@ func defaultTransport(skip bool, additionalCertsPath string, proxy string) http.
 		}
 	}
 
-	proxyURL, _ := url.Parse(proxy)
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		logrus.Warnf("could not parse proxy url (%s), error: %s", proxy, err.Error())
+		return &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig(skip, additionalCertsPath),
+		}
+	}
 
 	return &http.Transport{
 		Proxy:           http.ProxyURL(proxyURL),
 		TLSClientConfig: tlsConfig(skip, additionalCertsPath),
 	}
 }
-func GetGitClient(provider common.Provider, config *common.APIAccess) (client *scm.Client, err error) { //nolint:gocyclo,funlen
+func GetGitClient(provider common.Provider, repo string, config *common.APIAccess) (client *scm.Client, err error) { //nolint:gocyclo,funlen
 	switch provider {
 	case common.Github:
 		if config.Endpoint == "" {
@@ -129,6 +172,151 @@ func GetGitClient(provider common.Provider, config *common.APIAccess) (client *s
 				Transport: oauthTransport(token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
 			}
 		}
+	case common.GitLab:
+		if config.Endpoint == "" {
+			client = gitlab.NewDefault()
+		} else {
+			client, err = gitlab.New(config.Endpoint)
+			if err != nil {
+				logrus.Errorln("GetGitClient failure Gitlab", "endpoint", config.Endpoint, zap.Error(err))
+				return nil, err
+			}
+		}
+		// Personal, project and group access tokens are all accepted
+		// by the Gitlab API through the Private-Token header.
+		if config.AccessType != common.APIAccessToken {
+			return nil, status.Errorf(codes.Unimplemented, "Gitlab API access type %s not implemented yet", config.AccessType)
+		}
+		client.Client = &http.Client{
+			Transport: privateTokenTransport(config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+		}
+	case common.Bitbucket:
+		if config.Endpoint == "" {
+			client = bitbucket.NewDefault()
+		} else {
+			client, err = bitbucket.New(config.Endpoint)
+			if err != nil {
+				logrus.Errorln("GetGitClient failure Bitbucket", "endpoint", config.Endpoint, zap.Error(err))
+				return nil, err
+			}
+		}
+		switch config.AccessType {
+		case common.APIAccessUsernameToken:
+			// Username with an app password
+			client.Client = &http.Client{
+				Transport: basicAuthTransport(config.Username, config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+			}
+		case common.APIAccessToken:
+			// Repository, project and workspace access tokens
+			client.Client = &http.Client{
+				Transport: bearerTokenTransport(config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+			}
+		default:
+			return nil, status.Errorf(codes.Unimplemented, "Bitbucket API access type %s not implemented yet", config.AccessType)
+		}
+	case common.BitbucketServer:
+		// Bitbucket Server and Data Center are always self hosted
+		if config.Endpoint == "" {
+			logrus.Errorln("GetGitClient failure Bitbucket Server", "endpoint is missing")
+			return nil, status.Errorf(codes.InvalidArgument, "Bitbucket Server endpoint is missing")
+		}
+		client, err = stash.New(config.Endpoint)
+		if err != nil {
+			logrus.Errorln("GetGitClient failure Bitbucket Server", "endpoint", config.Endpoint, zap.Error(err))
+			return nil, err
+		}
+		switch config.AccessType {
+		case common.APIAccessUsernameToken:
+			client.Client = &http.Client{
+				Transport: basicAuthTransport(config.Username, config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+			}
+		case common.APIAccessToken:
+			// HTTP access tokens
+			client.Client = &http.Client{
+				Transport: bearerTokenTransport(config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+			}
+		default:
+			return nil, status.Errorf(codes.Unimplemented, "Bitbucket Server API access type %s not implemented yet", config.AccessType)
+		}
+	case common.AzureRepo:
+		azureRepo, err := ParseAzureRepo(repo)
+		if err != nil {
+			logrus.Errorln("GetGitClient failure Azure", "repo", repo, zap.Error(err))
+			return nil, err
+		}
+		endpoint := config.Endpoint
+		if endpoint == "" {
+			endpoint = azureEndpoint
+		}
+		client, err = azure.New(endpoint, azureRepo.Organization, azureRepo.Project)
+		if err != nil {
+			logrus.Errorln("GetGitClient failure Azure", "endpoint", endpoint, zap.Error(err))
+			return nil, err
+		}
+		// Azure personal access tokens are sent as the password
+		// of a basic auth header with an empty username.
+		if config.AccessType != common.APIAccessToken {
+			return nil, status.Errorf(codes.Unimplemented, "Azure API access type %s not implemented yet", config.AccessType)
+		}
+		client.Client = &http.Client{
+			Transport: basicAuthTransport("", config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+		}
+	case common.Gitea, common.Forgejo:
+		// Forgejo is a Gitea fork and serves the same API
+		if config.Endpoint == "" {
+			logrus.Errorln("GetGitClient failure", provider, "endpoint is missing")
+			return nil, status.Errorf(codes.InvalidArgument, "%s endpoint is missing", provider)
+		}
+		client, err = gitea.New(config.Endpoint)
+		if err != nil {
+			logrus.Errorln("GetGitClient failure", provider, "endpoint", config.Endpoint, zap.Error(err))
+			return nil, err
+		}
+		if config.AccessType != common.APIAccessToken {
+			return nil, status.Errorf(codes.Unimplemented, "%s API access type %s not implemented yet", provider, config.AccessType)
+		}
+		client.Client = &http.Client{
+			Transport: bearerTokenTransport(config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+		}
+	case common.Harness:
+		harnessRepo, err := ParseHarnessRepo(repo)
+		if err != nil {
+			logrus.Errorln("GetGitClient failure Harness", "repo", repo, zap.Error(err))
+			return nil, err
+		}
+		endpoint := config.Endpoint
+		if endpoint == "" {
+			endpoint = harnessEndpoint
+		}
+		client, err = harness.New(endpoint, harnessRepo.Account, harnessRepo.Organization, harnessRepo.Project)
+		if err != nil {
+			logrus.Errorln("GetGitClient failure Harness", "endpoint", endpoint, zap.Error(err))
+			return nil, err
+		}
+		if config.AccessType != common.APIAccessToken {
+			return nil, status.Errorf(codes.Unimplemented, "Harness API access type %s not implemented yet", config.AccessType)
+		}
+		client.Client = &http.Client{
+			Transport: apiKeyTransport(config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+		}
+	case common.Gerrit:
+		if config.Endpoint == "" {
+			logrus.Errorln("GetGitClient failure Gerrit", "endpoint is missing")
+			return nil, status.Errorf(codes.InvalidArgument, "Gerrit endpoint is missing")
+		}
+		client, err = gerrit.New(config.Endpoint)
+		if err != nil {
+			logrus.Errorln("GetGitClient failure Gerrit", "endpoint", config.Endpoint, zap.Error(err))
+			return nil, err
+		}
+		// Gerrit authenticates with the username and HTTP password of
+		// the account, using digest or basic auth depending on the server.
+		if config.AccessType != common.APIAccessUsernameToken {
+			return nil, status.Errorf(codes.Unimplemented, "Gerrit API access type %s not implemented yet", config.AccessType)
+		}
+		client.Client = &http.Client{
+			Transport: challengeAuthTransport(config.Username, config.Token, SkipSSLVerify, AdditionalCertsPath, config.ProxyURL),
+		}
 	default:
 		logrus.Errorln("GetGitClient unsupported git provider", "endpoint", config.Endpoint)
 		return nil, status.Errorf(codes.InvalidArgument, "Unsupported git provider")
@@ -136,6 +324,43 @@ func GetGitClient(provider common.Provider, config *common.APIAccess) (client *s
 	return client, nil
 }
 
+// HasAPIAccess returns true when the operations of the connector can go
+// through the provider API instead of plain git.
+func HasAPIAccess(provider common.Provider, params *common.GitConnectorParams) bool {
+	return params.APIAccess != nil && provider != common.GenericGit && provider != common.CodeCommit
+}
+
+// WithAPIOrGit runs withAPI when the operations of the connector can go
+// through the provider API and withGit otherwise. Operations the provider
+// API does not support fall back to git as well.
+func WithAPIOrGit(provider common.Provider, params *common.GitConnectorParams, withAPI, withGit func() error) error {
+	if !HasAPIAccess(provider, params) {
+		return withGit()
+	}
+	err := withAPI()
+	if errors.Is(err, scm.ErrNotSupported) {
+		logrus.Infof("Provider %s API does not support the operation, using git instead", provider)
+		return withGit()
+	}
+	return err
+}
+
+// GetRepoClient returns the provider API client along with the identifier of
+// the connector repository in that API.
+func GetRepoClient(provider common.Provider, params *common.GitConnectorParams) (*scm.Client, string, error) {
+	slug, err := RepoSlug(provider, params.Repo)
+	if err != nil {
+		logrus.Errorf("Failed to parse repository url: %v", err)
+		return nil, "", err
+	}
+	client, err := GetGitClient(provider, params.Repo, params.APIAccess)
+	if err != nil {
+		logrus.Errorf("Failed to create git provider client: %v", err)
+		return nil, "", err
+	}
+	return client, slug, nil
+}
+
 // Finds out if provider is Github Anonymous
 func IsGithubAnonymous() (out bool) {
 	return false
@@ -0,0 +1,149 @@
+package gitclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateBranch pushes a new branch pointing to the commit the ref resolves
+// to. An existing branch is left as is.
+func (gc *GitClient) CreateBranch(name, ref string) (*object.Commit, error) {
+	branch := plumbing.NewBranchReferenceName(name)
+	refs, err := gc.ListRefs()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range refs {
+		if r.Name() == branch {
+			return nil, fmt.Errorf("branch %s already exists", name)
+		}
+	}
+	repo, commit, err := gc.fetchCommit(refs, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(branch, commit.Hash)); err != nil {
+		return nil, err
+	}
+	// the leading + is left out, so that a branch created meanwhile is not moved
+	spec := config.RefSpec(fmt.Sprintf("%s:%s", branch, branch))
+	logrus.Infof("Pushing branch %s at %s", name, commit.Hash)
+	if err := gc.push(repo, spec); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// DeleteBranch pushes the deletion of the branch.
+func (gc *GitClient) DeleteBranch(name string) error {
+	branch := plumbing.NewBranchReferenceName(name)
+	if exists, err := gc.hasRef(branch); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("branch %s not found", name)
+	}
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return err
+	}
+	spec := config.RefSpec(":" + branch.String())
+	logrus.Infof("Pushing deletion of branch %s", name)
+	return gc.push(repo, spec)
+}
+
+// fetchCommit fetches the commit the ref resolves to without its history,
+// which is all the push of a new ref to it needs. A commit SHA no ref points
+// to is fetched on its own where the remote allows it, and with the full
+// history otherwise.
+func (gc *GitClient) fetchCommit(refs []*plumbing.Reference, ref string) (*git.Repository, *object.Commit, error) {
+	if ref == "" || FindRef(refs, ref) != "" {
+		return gc.Clone(ref, 1)
+	}
+	if !plumbing.IsHash(ref) {
+		// a short SHA is only resolved against the full history
+		return gc.Clone(ref, 0)
+	}
+	hash := plumbing.NewHash(ref)
+	for _, r := range refs {
+		if r.Hash() == hash && r.Name() != plumbing.HEAD {
+			return gc.Clone(strings.TrimSuffix(r.Name().String(), PeeledSuffix), 1)
+		}
+	}
+
+	url, auth, err := gc.remote()
+	if err != nil {
+		logrus.Error(err.Error())
+		return nil, nil, err
+	}
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	remote, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url}})
+	if err != nil {
+		return nil, nil, err
+	}
+	logrus.Infof("Fetching commit %s", ref)
+	err = remote.Fetch(&git.FetchOptions{
+		Auth:     auth,
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:refs/commits/%s", hash, hash))},
+		Depth:    1,
+		Tags:     git.NoTags,
+	})
+	if errors.Is(err, git.ErrExactSHA1NotSupported) {
+		logrus.Infof("Remote does not serve commit %s on its own, cloning the full history", ref)
+		return gc.Clone(ref, 0)
+	}
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, nil, err
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find commit for ref %s: %w", ref, err)
+	}
+	return repo, commit, nil
+}
+
+// hasRef reports whether the remote advertises the reference.
+func (gc *GitClient) hasRef(name plumbing.ReferenceName) (bool, error) {
+	refs, err := gc.ListRefs()
+	if err != nil {
+		return false, err
+	}
+	for _, ref := range refs {
+		if ref.Name() == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// push pushes the refspecs from the repository to the remote.
+func (gc *GitClient) push(repo *git.Repository, specs ...config.RefSpec) error {
+	url, auth, err := gc.remote()
+	if err != nil {
+		logrus.Error(err.Error())
+		return err
+	}
+	remote, err := repo.CreateRemoteAnonymous(&config.RemoteConfig{Name: "anonymous", URLs: []string{url}})
+	if err != nil {
+		return err
+	}
+	err = remote.Push(&git.PushOptions{
+		RemoteName: "anonymous",
+		RefSpecs:   specs,
+		Auth:       auth,
+	})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package gitclient
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitHTTP "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/sirupsen/logrus"
+)
+
+// githubAppUsername is the username git expects alongside a Github App
+// installation token.
+const githubAppUsername = "x-access-token"
+
+// PeeledSuffix marks the commit an annotated tag points to in
+// the ref advertisement.
+const PeeledSuffix = "^{}"
+
+// New returns a GitClient for the auth type configured on the connector.
+func New(params *common.GitConnectorParams) (*GitClient, error) {
+	switch params.AuthType {
+	case common.AuthTypeHttp:
+		if params.HTTPAuth == nil {
+			return nil, fmt.Errorf("HTTP Auth config is missing")
+		}
+		return NewHttp(params.Repo, params.HTTPAuth), nil
+	case common.AuthTypeSsh:
+		if params.SSHAuth == nil {
+			return nil, fmt.Errorf("SSH Auth is missing")
+		}
+		return NewSsh(params.Repo, params.SSHAuth), nil
+	}
+	return nil, fmt.Errorf("Auth type %v is not supported", params.AuthType)
+}
+
+// ListRefs returns the references advertised by the remote, the same way
+// git ls-remote does. The peeled references of annotated tags are appended,
+// named after the tag with a ^{} suffix.
+func (gc *GitClient) ListRefs() ([]*plumbing.Reference, error) {
+	repo, auth, err := gc.remote()
+	if err != nil {
+		logrus.Error(err.Error())
+		return nil, err
+	}
+	remote := git.NewRemote(nil, &config.RemoteConfig{Name: "origin",
+		URLs: []string{repo},
+	})
+	return remote.List(&git.ListOptions{
+		Auth:          auth,
+		PeelingOption: git.AppendPeeled,
+	})
+}
+
+// remote returns the url of the repository and the auth method git
+// should use to reach it.
+func (gc *GitClient) remote() (string, transport.AuthMethod, error) {
+	if gc.SSHAuth != nil {
+		auth, err := gc.getSSHKey()
+		if err != nil {
+			return "", nil, err
+		}
+		return gc.Repo, auth, nil
+	}
+	if gc.HTTPAuth == nil {
+		return "", nil, fmt.Errorf("HTTP Auth config is missing")
+	}
+	repo, err := gc.getHttpRepo()
+	if err != nil {
+		return "", nil, err
+	}
+	if gc.HTTPAuth.AuthMethod == common.HTTPAuthAnonymous {
+		return repo, nil, nil
+	}
+	auth, err := gc.getHttpAuth(repo)
+	if err != nil {
+		return "", nil, err
+	}
+	return repo, auth, nil
+}
+
+// getGithubAppAuth exchanges the Github App credentials for an installation
+// token that git accepts as a password.
+func (gc *GitClient) getGithubAppAuth() (*gitHTTP.BasicAuth, error) {
+	if gc.HTTPAuth.GithubApp == nil {
+		return nil, fmt.Errorf("Github App details not provided")
+	}
+	token, err := GetTokenForGithubApp(gc.HTTPAuth.GithubApp)
+	if err != nil {
+		return nil, err
+	}
+	return &gitHTTP.BasicAuth{
+		Username: githubAppUsername,
+		Password: token,
+	}, nil
+}
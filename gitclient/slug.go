@@ -0,0 +1,66 @@
+package gitclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/harness/git-connector-cgi/common"
+)
+
+// RepoSlug returns the repository identifier the provider API expects,
+// derived from the clone URL of the repository. For most providers this is
+// the {namespace}/{name} path of the URL.
+func RepoSlug(provider common.Provider, repo string) (string, error) {
+	switch provider {
+	case common.AzureRepo:
+		azureRepo, err := ParseAzureRepo(repo)
+		if err != nil {
+			return "", err
+		}
+		return azureRepo.Name, nil
+	case common.Harness:
+		harnessRepo, err := ParseHarnessRepo(repo)
+		if err != nil {
+			return "", err
+		}
+		return harnessRepo.Name, nil
+	case common.BitbucketServer:
+		bitbucketRepo, err := ParseBitbucketServerRepo(repo)
+		if err != nil {
+			return "", err
+		}
+		return bitbucketRepo.Project + "/" + bitbucketRepo.Name, nil
+	}
+
+	path, err := repoPath(repo)
+	if err != nil {
+		return "", err
+	}
+	parts := splitPath(strings.TrimSuffix(path, ".git"))
+	// authenticated Gerrit clone urls are served under /a/{project}
+	if provider == common.Gerrit && len(parts) > 1 && parts[0] == "a" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("repository url %s is missing the repository name", repo)
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// repoPath returns the path of an http(s), ssh or scp-like git URL.
+func repoPath(repo string) (string, error) {
+	if strings.Contains(repo, "://") {
+		u, err := url.Parse(repo)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse repository url %s: %w", repo, err)
+		}
+		return u.Path, nil
+	}
+	// scp-like syntax, [user@]host:path
+	idx := strings.Index(repo, ":")
+	if idx < 0 {
+		return "", fmt.Errorf("repository url %s is not valid", repo)
+	}
+	return repo[idx+1:], nil
+}
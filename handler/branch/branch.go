@@ -0,0 +1,94 @@
+package branch
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleListBranches(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ListBranchesResponse {
+	in := new(common.ListBranchesInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid list branches params: %v", err)
+		return common.ListBranchesResponse{Response: common.NewFailureResponse("Invalid list branches params", err)}
+	}
+	in.Normalize()
+
+	var (
+		branches []common.Branch
+		nextPage int
+		err      error
+	)
+	if gitclient.HasAPIAccess(provider, config) {
+		branches, nextPage, err = listBranchesWithAPI(provider, config, in)
+	} else {
+		branches, nextPage, err = listBranchesWithGit(config, in)
+	}
+	if err != nil {
+		logrus.Errorf("Failed listing branches: %v", err)
+		return common.ListBranchesResponse{Response: common.NewFailureResponse("Failed listing branches", err)}
+	}
+	return common.ListBranchesResponse{
+		Response: common.NewSuccessResponse(),
+		Branches: branches,
+		NextPage: nextPage,
+	}
+}
+
+func listBranchesWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.ListBranchesInput) ([]common.Branch, int, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, 0, err
+	}
+	if client.Git == nil {
+		logrus.Infof("Provider %s has no branch API, listing branches using git", provider)
+		return listBranchesWithGit(config, in)
+	}
+	logrus.Info("Listing branches using the provider API")
+	refs, response, err := client.Git.ListBranchesV2(context.Background(), repo, scm.BranchListOptions{
+		SearchTerm:      in.Filter,
+		PageListOptions: scm.ListOptions{Page: in.Page, Size: in.PerPage},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	branches := []common.Branch{}
+	for _, ref := range refs {
+		// not every provider supports searching branches
+		if common.MatchFilter(ref.Name, in.Filter) {
+			branches = append(branches, common.Branch{Name: ref.Name, Sha: ref.Sha})
+		}
+	}
+	return branches, response.Page.Next, nil
+}
+
+func listBranchesWithGit(config *common.GitConnectorParams, in *common.ListBranchesInput) ([]common.Branch, int, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, 0, err
+	}
+	logrus.Info("Listing branches using git")
+	refs, err := gitClient.ListRefs()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	branches := []common.Branch{}
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && common.MatchFilter(ref.Name().Short(), in.Filter) {
+			branches = append(branches, common.Branch{Name: ref.Name().Short(), Sha: ref.Hash().String()})
+		}
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+
+	start, end, nextPage := in.PageInput.Bounds(len(branches))
+	return branches[start:end], nextPage, nil
+}
@@ -0,0 +1,131 @@
+package branch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleCreateBranch(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.CreateBranchResponse {
+	in := new(common.CreateBranchInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid create branch params: %v", err)
+		return common.CreateBranchResponse{Response: common.NewFailureResponse("Invalid create branch params", err)}
+	}
+	if in.Name == "" {
+		err := errors.New("branch name is required")
+		logrus.Errorf("Invalid create branch params: %v", err)
+		return common.CreateBranchResponse{Response: common.NewFailureResponse("Invalid create branch params", err)}
+	}
+
+	var branch *common.Branch
+	err := gitclient.WithAPIOrGit(provider, config, func() (err error) {
+		branch, err = createBranchWithAPI(provider, config, in)
+		return err
+	}, func() (err error) {
+		branch, err = createBranchWithGit(config, in)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("Failed creating branch %s: %v", in.Name, err)
+		return common.CreateBranchResponse{Response: common.NewFailureResponse("Failed creating branch", err)}
+	}
+	return common.CreateBranchResponse{
+		Response: common.NewSuccessResponse(),
+		Branch:   branch,
+	}
+}
+
+func HandleDeleteBranch(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.DeleteBranchResponse {
+	in := new(common.DeleteBranchInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid delete branch params: %v", err)
+		return common.DeleteBranchResponse{Response: common.NewFailureResponse("Invalid delete branch params", err)}
+	}
+	if in.Name == "" {
+		err := errors.New("branch name is required")
+		logrus.Errorf("Invalid delete branch params: %v", err)
+		return common.DeleteBranchResponse{Response: common.NewFailureResponse("Invalid delete branch params", err)}
+	}
+
+	err := gitclient.WithAPIOrGit(provider, config, func() error {
+		return deleteBranchWithAPI(provider, config, in)
+	}, func() error {
+		return deleteBranchWithGit(config, in)
+	})
+	if err != nil {
+		logrus.Errorf("Failed deleting branch %s: %v", in.Name, err)
+		return common.DeleteBranchResponse{Response: common.NewFailureResponse("Failed deleting branch", err)}
+	}
+	return common.DeleteBranchResponse{Response: common.NewSuccessResponse()}
+}
+
+func createBranchWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.CreateBranchInput) (*common.Branch, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	if client.Git == nil {
+		return nil, scm.ErrNotSupported
+	}
+	ctx := context.Background()
+	logrus.Info("Creating branch using the provider API")
+	sha, err := gitclient.ResolveCommit(ctx, client, repo, in.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.Git.CreateBranch(ctx, repo, &scm.ReferenceInput{Name: in.Name, Sha: sha}); err != nil {
+		return nil, err
+	}
+	return &common.Branch{Name: in.Name, Sha: sha}, nil
+}
+
+func createBranchWithGit(config *common.GitConnectorParams, in *common.CreateBranchInput) (*common.Branch, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Info("Creating branch using git")
+	commit, err := gitClient.CreateBranch(in.Name, in.Ref)
+	if err != nil {
+		return nil, err
+	}
+	return &common.Branch{Name: in.Name, Sha: commit.Hash.String()}, nil
+}
+
+// deleteBranchWithAPI deletes the branch through the APIs of Github and
+// Gitlab. go-scm cannot delete branches, so other providers use git.
+func deleteBranchWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.DeleteBranchInput) error {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return err
+	}
+	var path string
+	switch client.Driver {
+	case scm.DriverGithub:
+		path = fmt.Sprintf("repos/%s/git/refs/heads/%s", repo, gitclient.EscapePath(in.Name))
+	case scm.DriverGitlab:
+		path = fmt.Sprintf("api/v4/projects/%s/repository/branches/%s", url.PathEscape(repo), url.PathEscape(in.Name))
+	default:
+		return scm.ErrNotSupported
+	}
+	logrus.Info("Deleting branch using the provider API")
+	_, err = gitclient.DoJSON(context.Background(), client, "DELETE", path, nil, nil)
+	return err
+}
+
+func deleteBranchWithGit(config *common.GitConnectorParams, in *common.DeleteBranchInput) error {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return err
+	}
+	logrus.Info("Deleting branch using git")
+	return gitClient.DeleteBranch(in.Name)
+}
@@ -0,0 +1,175 @@
+package commit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleListCommits(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ListCommitsResponse {
+	in := new(common.ListCommitsInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid list commits params: %v", err)
+		return common.ListCommitsResponse{Response: common.NewFailureResponse("Invalid list commits params", err)}
+	}
+	in.Normalize()
+
+	var (
+		commits  []common.Commit
+		nextPage int
+	)
+	err := gitclient.WithAPIOrGit(provider, config, func() (err error) {
+		commits, nextPage, err = listCommitsWithAPI(provider, config, in)
+		return err
+	}, func() (err error) {
+		commits, nextPage, err = listCommitsWithGit(config, in)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("Failed listing commits: %v", err)
+		return common.ListCommitsResponse{Response: common.NewFailureResponse("Failed listing commits", err)}
+	}
+	return common.ListCommitsResponse{
+		Response: common.NewSuccessResponse(),
+		Commits:  commits,
+		NextPage: nextPage,
+	}
+}
+
+func HandleGetCommit(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.GetCommitResponse {
+	in := new(common.GetCommitInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid get commit params: %v", err)
+		return common.GetCommitResponse{Response: common.NewFailureResponse("Invalid get commit params", err)}
+	}
+
+	var commit *common.Commit
+	err := gitclient.WithAPIOrGit(provider, config, func() (err error) {
+		commit, err = getCommitWithAPI(provider, config, in)
+		return err
+	}, func() (err error) {
+		commit, err = getCommitWithGit(config, in)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("Failed getting commit: %v", err)
+		return common.GetCommitResponse{Response: common.NewFailureResponse("Failed getting commit", err)}
+	}
+	return common.GetCommitResponse{
+		Response: common.NewSuccessResponse(),
+		Commit:   commit,
+	}
+}
+
+func listCommitsWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.ListCommitsInput) ([]common.Commit, int, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, 0, err
+	}
+	if client.Git == nil {
+		return nil, 0, scm.ErrNotSupported
+	}
+	ctx := context.Background()
+	logrus.Info("Listing commits using the provider API")
+	switch client.Driver {
+	case scm.DriverGithub:
+		return listGithubCommits(ctx, client, repo, in)
+	case scm.DriverGitlab:
+		return listGitlabCommits(ctx, client, repo, in)
+	}
+
+	out, response, err := client.Git.ListCommits(ctx, repo, scm.CommitListOptions{
+		Ref:  in.Ref,
+		Path: in.Path,
+		Page: in.Page,
+		Size: in.PerPage,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	commits := []common.Commit{}
+	for _, c := range out {
+		// the generic API cannot filter on dates
+		if inRange(c.Committer.Date, in.Since, in.Until) {
+			commits = append(commits, convertCommit(c))
+		}
+	}
+	return commits, response.Page.Next, nil
+}
+
+func getCommitWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.GetCommitInput) (*common.Commit, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	if client.Git == nil {
+		return nil, scm.ErrNotSupported
+	}
+	ctx := context.Background()
+	logrus.Info("Getting commit using the provider API")
+	ref, err := gitclient.DefaultRef(ctx, client, repo, in.Ref)
+	if err != nil {
+		return nil, err
+	}
+	switch client.Driver {
+	case scm.DriverGithub:
+		return getGithubCommit(ctx, client, repo, ref)
+	case scm.DriverGitlab:
+		return getGitlabCommit(ctx, client, repo, ref)
+	}
+
+	// the generic API reports neither the parents nor the line stats
+	out, err := gitclient.FindCommit(ctx, client, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	commit := convertCommit(out)
+	opts := scm.ListOptions{Page: 1, Size: common.MaxPerPage}
+	for {
+		changes, response, err := client.Git.ListChanges(ctx, repo, out.Sha, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			commit.Files = append(commit.Files, gitclient.ScmChangedFile(change))
+		}
+		if response == nil || response.Page.Next <= opts.Page {
+			break
+		}
+		opts.Page = response.Page.Next
+	}
+	return &commit, nil
+}
+
+func convertCommit(from *scm.Commit) common.Commit {
+	return common.Commit{
+		Sha:       from.Sha,
+		Message:   from.Message,
+		Author:    convertSignature(from.Author),
+		Committer: convertSignature(from.Committer),
+	}
+}
+
+func convertSignature(from scm.Signature) common.Signature {
+	return common.Signature{
+		Name:  from.Name,
+		Email: from.Email,
+		Date:  from.Date,
+	}
+}
+
+// inRange reports whether the date lies within the optional bounds.
+func inRange(date time.Time, since, until *time.Time) bool {
+	if since != nil && date.Before(*since) {
+		return false
+	}
+	if until != nil && date.After(*until) {
+		return false
+	}
+	return true
+}
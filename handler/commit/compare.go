@@ -0,0 +1,130 @@
+package commit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleCompareRefs(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.CompareRefsResponse {
+	in := new(common.CompareRefsInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid compare refs params: %v", err)
+		return common.CompareRefsResponse{Response: common.NewFailureResponse("Invalid compare refs params", err)}
+	}
+	if in.Head == "" {
+		err := errors.New("head ref is required")
+		logrus.Errorf("Invalid compare refs params: %v", err)
+		return common.CompareRefsResponse{Response: common.NewFailureResponse("Invalid compare refs params", err)}
+	}
+
+	var (
+		response *common.CompareRefsResponse
+		err      error
+	)
+	if gitclient.HasAPIAccess(provider, config) {
+		response, err = compareRefsWithAPI(provider, config, in)
+	} else {
+		response, err = compareRefsWithGit(config, in)
+	}
+	if err != nil {
+		logrus.Errorf("Failed comparing refs: %v", err)
+		return common.CompareRefsResponse{Response: common.NewFailureResponse("Failed comparing refs", err)}
+	}
+	response.Response = common.NewSuccessResponse()
+	return *response
+}
+
+func compareRefsWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.CompareRefsInput) (*common.CompareRefsResponse, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	switch client.Driver {
+	case scm.DriverGithub:
+		logrus.Info("Comparing refs using the provider API")
+		return compareGithubRefs(ctx, client, repo, in)
+	case scm.DriverGitlab:
+		logrus.Info("Comparing refs using the provider API")
+		return compareGitlabRefs(ctx, client, repo, in)
+	}
+	// the generic API reports neither the merge base nor the commit counts
+	logrus.Infof("Provider %s has no compare API, comparing refs using git", provider)
+	return compareRefsWithGit(config, in)
+}
+
+func compareRefsWithGit(config *common.GitConnectorParams, in *common.CompareRefsInput) (*common.CompareRefsResponse, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Info("Comparing refs using git")
+	_, commits, err := gitClient.FetchRefs(in.Base, in.Head)
+	if err != nil {
+		return nil, err
+	}
+	base, head := commits[0], commits[1]
+
+	bases, err := base.MergeBase(head)
+	if err != nil {
+		return nil, err
+	}
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("%s and %s have no common history", in.Base, in.Head)
+	}
+	mergeBase := bases[0]
+
+	response := &common.CompareRefsResponse{
+		BaseSha:      base.Hash.String(),
+		HeadSha:      head.Hash.String(),
+		MergeBaseSha: mergeBase.Hash.String(),
+	}
+	if response.AheadBy, err = countCommits(head, base); err != nil {
+		return nil, err
+	}
+	if response.BehindBy, err = countCommits(base, head); err != nil {
+		return nil, err
+	}
+
+	// like the provider APIs, the changes are those of head since the merge base
+	from, err := mergeBase.Tree()
+	if err != nil {
+		return nil, err
+	}
+	to, err := head.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if response.Files, err = gitclient.DiffTrees(context.Background(), from, to); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// countCommits counts the commits reachable from the commit that are not
+// reachable from the excluded one, like git rev-list --count exclude..commit.
+func countCommits(commit, exclude *object.Commit) (int, error) {
+	excluded := map[plumbing.Hash]bool{}
+	err := object.NewCommitPreorderIter(exclude, nil, nil).ForEach(func(c *object.Commit) error {
+		excluded[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = object.NewCommitPreorderIter(commit, excluded, nil).ForEach(func(*object.Commit) error {
+		count++
+		return nil
+	})
+	return count, err
+}
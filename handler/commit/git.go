@@ -0,0 +1,109 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func listCommitsWithGit(config *common.GitConnectorParams, in *common.ListCommitsInput) ([]common.Commit, int, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, 0, err
+	}
+	logrus.Info("Listing commits using git")
+	repo, head, err := gitClient.Clone(in.Ref, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	options := &git.LogOptions{
+		From:  head.Hash,
+		Since: in.Since,
+		Until: in.Until,
+	}
+	if dir := strings.Trim(in.Path, "/"); dir != "" {
+		options.PathFilter = func(path string) bool {
+			return path == dir || strings.HasPrefix(path, dir+"/")
+		}
+	}
+	iter, err := repo.Log(options)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer iter.Close()
+
+	// walk one commit past the page to know whether there is a next one
+	skip := (in.Page - 1) * in.PerPage
+	commits := []common.Commit{}
+	nextPage := 0
+	err = iter.ForEach(func(c *object.Commit) error {
+		if skip > 0 {
+			skip--
+			return nil
+		}
+		if len(commits) == in.PerPage {
+			nextPage = in.Page + 1
+			return storer.ErrStop
+		}
+		commits = append(commits, *convertGitCommit(c))
+		return nil
+	})
+	if err != nil && !errors.Is(err, storer.ErrStop) {
+		return nil, 0, err
+	}
+	return commits, nextPage, nil
+}
+
+func getCommitWithGit(config *common.GitConnectorParams, in *common.GetCommitInput) (*common.Commit, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Info("Getting commit using git")
+	// the parent is needed to diff the commit
+	_, c, err := gitClient.Clone(in.Ref, 2)
+	if err != nil {
+		return nil, err
+	}
+	commit := convertGitCommit(c)
+
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	if commit.Files, err = gitclient.DiffTrees(context.Background(), parentTree, tree); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+func convertGitCommit(from *object.Commit) *common.Commit {
+	commit := &common.Commit{
+		Sha:       from.Hash.String(),
+		Message:   from.Message,
+		Author:    common.Signature{Name: from.Author.Name, Email: from.Author.Email, Date: from.Author.When},
+		Committer: common.Signature{Name: from.Committer.Name, Email: from.Committer.Email, Date: from.Committer.When},
+	}
+	for _, parent := range from.ParentHashes {
+		commit.Parents = append(commit.Parents, parent.String())
+	}
+	return commit
+}
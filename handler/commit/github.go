@@ -0,0 +1,131 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+type githubCommit struct {
+	Sha    string `json:"sha"`
+	Commit struct {
+		Message   string          `json:"message"`
+		Author    githubSignature `json:"author"`
+		Committer githubSignature `json:"committer"`
+	} `json:"commit"`
+	Parents []struct {
+		Sha string `json:"sha"`
+	} `json:"parents"`
+	Files []*gitclient.GithubFile `json:"files"`
+}
+
+type githubSignature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+func listGithubCommits(ctx context.Context, client *scm.Client, repo string, in *common.ListCommitsInput) ([]common.Commit, int, error) {
+	params := url.Values{}
+	params.Set("page", fmt.Sprint(in.Page))
+	params.Set("per_page", fmt.Sprint(in.PerPage))
+	if in.Ref != "" {
+		params.Set("sha", in.Ref)
+	}
+	if in.Path != "" {
+		params.Set("path", in.Path)
+	}
+	if in.Since != nil {
+		params.Set("since", in.Since.Format(time.RFC3339))
+	}
+	if in.Until != nil {
+		params.Set("until", in.Until.Format(time.RFC3339))
+	}
+	out := []*githubCommit{}
+	response, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/commits?%s", repo, params.Encode()), nil, &out)
+	if err != nil {
+		return nil, 0, err
+	}
+	commits := []common.Commit{}
+	for _, c := range out {
+		commits = append(commits, *convertGithubCommit(c))
+	}
+	return commits, response.Page.Next, nil
+}
+
+func getGithubCommit(ctx context.Context, client *scm.Client, repo, ref string) (*common.Commit, error) {
+	out := new(githubCommit)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/commits/%s", repo, url.PathEscape(ref)), nil, out); err != nil {
+		return nil, err
+	}
+	commit := convertGithubCommit(out)
+	commit.Files = gitclient.GithubChangedFiles(out.Files)
+	return commit, nil
+}
+
+func convertGithubCommit(from *githubCommit) *common.Commit {
+	commit := &common.Commit{
+		Sha:       from.Sha,
+		Message:   from.Commit.Message,
+		Author:    common.Signature(from.Commit.Author),
+		Committer: common.Signature(from.Commit.Committer),
+	}
+	for _, parent := range from.Parents {
+		commit.Parents = append(commit.Parents, parent.Sha)
+	}
+	return commit
+}
+
+// githubCompareFilesLimit is the most files Github documents listing for
+// a comparison, more are silently left out.
+const githubCompareFilesLimit = 300
+
+type githubComparison struct {
+	BaseCommit struct {
+		Sha string `json:"sha"`
+	} `json:"base_commit"`
+	MergeBaseCommit struct {
+		Sha string `json:"sha"`
+	} `json:"merge_base_commit"`
+	AheadBy  int `json:"ahead_by"`
+	BehindBy int `json:"behind_by"`
+	// Files is left out altogether when the diff is too large
+	Files []*gitclient.GithubFile `json:"files"`
+}
+
+func compareGithubRefs(ctx context.Context, client *scm.Client, repo string, in *common.CompareRefsInput) (*common.CompareRefsResponse, error) {
+	base, err := gitclient.DefaultRef(ctx, client, repo, in.Base)
+	if err != nil {
+		return nil, err
+	}
+	out := new(githubComparison)
+	path := fmt.Sprintf("repos/%s/compare/%s...%s", repo, url.PathEscape(base), url.PathEscape(in.Head))
+	if _, err := gitclient.DoJSON(ctx, client, "GET", path, nil, out); err != nil {
+		return nil, err
+	}
+	// the comparison lists a limited number of commits, so the
+	// last one is not necessarily the head
+	head, err := gitclient.ResolveCommit(ctx, client, repo, in.Head)
+	if err != nil {
+		return nil, err
+	}
+	truncated := (out.Files == nil && out.AheadBy > 0) || len(out.Files) == githubCompareFilesLimit
+	if truncated {
+		logrus.Warnf("Github does not list all the files changed between %s and %s", base, in.Head)
+	}
+	return &common.CompareRefsResponse{
+		BaseSha:        out.BaseCommit.Sha,
+		HeadSha:        head,
+		MergeBaseSha:   out.MergeBaseCommit.Sha,
+		AheadBy:        out.AheadBy,
+		BehindBy:       out.BehindBy,
+		Files:          gitclient.GithubChangedFiles(out.Files),
+		FilesTruncated: truncated,
+	}, nil
+}
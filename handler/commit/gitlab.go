@@ -0,0 +1,140 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+type gitlabCommit struct {
+	ID             string    `json:"id"`
+	Message        string    `json:"message"`
+	AuthorName     string    `json:"author_name"`
+	AuthorEmail    string    `json:"author_email"`
+	AuthoredDate   time.Time `json:"authored_date"`
+	CommitterName  string    `json:"committer_name"`
+	CommitterEmail string    `json:"committer_email"`
+	CommittedDate  time.Time `json:"committed_date"`
+	ParentIDs      []string  `json:"parent_ids"`
+}
+
+func listGitlabCommits(ctx context.Context, client *scm.Client, repo string, in *common.ListCommitsInput) ([]common.Commit, int, error) {
+	params := url.Values{}
+	params.Set("page", fmt.Sprint(in.Page))
+	params.Set("per_page", fmt.Sprint(in.PerPage))
+	if in.Ref != "" {
+		params.Set("ref_name", in.Ref)
+	}
+	if in.Path != "" {
+		params.Set("path", in.Path)
+	}
+	if in.Since != nil {
+		params.Set("since", in.Since.Format(time.RFC3339))
+	}
+	if in.Until != nil {
+		params.Set("until", in.Until.Format(time.RFC3339))
+	}
+	out := []*gitlabCommit{}
+	response, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("api/v4/projects/%s/repository/commits?%s", url.PathEscape(repo), params.Encode()), nil, &out)
+	if err != nil {
+		return nil, 0, err
+	}
+	commits := []common.Commit{}
+	for _, c := range out {
+		commits = append(commits, *convertGitlabCommit(c))
+	}
+	return commits, response.Page.Next, nil
+}
+
+// getGitlabCommit gets the commit along with its diff, which is paginated
+// separately.
+func getGitlabCommit(ctx context.Context, client *scm.Client, repo, ref string) (*common.Commit, error) {
+	project := url.PathEscape(repo)
+	out := new(gitlabCommit)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("api/v4/projects/%s/repository/commits/%s", project, url.PathEscape(ref)), nil, out); err != nil {
+		return nil, err
+	}
+	commit := convertGitlabCommit(out)
+
+	for page := 1; page != 0; {
+		diffs := []*gitclient.GitlabDiff{}
+		path := fmt.Sprintf("api/v4/projects/%s/repository/commits/%s/diff?page=%d&per_page=%d", project, out.ID, page, common.MaxPerPage)
+		response, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &diffs)
+		if err != nil {
+			return nil, err
+		}
+		commit.Files = append(commit.Files, gitclient.GitlabChangedFiles(diffs)...)
+		page = response.Page.Next
+	}
+	return commit, nil
+}
+
+func convertGitlabCommit(from *gitlabCommit) *common.Commit {
+	return &common.Commit{
+		Sha:       from.ID,
+		Message:   from.Message,
+		Author:    common.Signature{Name: from.AuthorName, Email: from.AuthorEmail, Date: from.AuthoredDate},
+		Committer: common.Signature{Name: from.CommitterName, Email: from.CommitterEmail, Date: from.CommittedDate},
+		Parents:   from.ParentIDs,
+	}
+}
+
+type gitlabComparison struct {
+	Commits []*gitlabCommit         `json:"commits"`
+	Diffs   []*gitclient.GitlabDiff `json:"diffs"`
+	// the diffs are incomplete when the comparison timed out
+	// or exceeded the diff limits of the instance
+	CompareTimeout bool `json:"compare_timeout"`
+	Overflow       bool `json:"overflow"`
+}
+
+// compareGitlabRefs compares the refs both ways, as Gitlab only reports
+// the commits of the head since the merge base.
+func compareGitlabRefs(ctx context.Context, client *scm.Client, repo string, in *common.CompareRefsInput) (*common.CompareRefsResponse, error) {
+	project := url.PathEscape(repo)
+	base, err := gitclient.DefaultRef(ctx, client, repo, in.Base)
+	if err != nil {
+		return nil, err
+	}
+	response := &common.CompareRefsResponse{}
+	if response.BaseSha, err = gitclient.ResolveCommit(ctx, client, repo, base); err != nil {
+		return nil, err
+	}
+	if response.HeadSha, err = gitclient.ResolveCommit(ctx, client, repo, in.Head); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("refs[]", response.BaseSha)
+	params.Add("refs[]", response.HeadSha)
+	mergeBase := new(gitlabCommit)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("api/v4/projects/%s/repository/merge_base?%s", project, params.Encode()), nil, mergeBase); err != nil {
+		return nil, err
+	}
+	response.MergeBaseSha = mergeBase.ID
+
+	ahead := new(gitlabComparison)
+	path := fmt.Sprintf("api/v4/projects/%s/repository/compare?from=%s&to=%s", project, response.BaseSha, response.HeadSha)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", path, nil, ahead); err != nil {
+		return nil, err
+	}
+	behind := new(gitlabComparison)
+	path = fmt.Sprintf("api/v4/projects/%s/repository/compare?from=%s&to=%s", project, response.HeadSha, response.BaseSha)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", path, nil, behind); err != nil {
+		return nil, err
+	}
+	response.AheadBy = len(ahead.Commits)
+	response.BehindBy = len(behind.Commits)
+	response.Files = gitclient.GitlabChangedFiles(ahead.Diffs)
+	response.FilesTruncated = ahead.CompareTimeout || ahead.Overflow
+	if response.FilesTruncated {
+		logrus.Warnf("Gitlab does not list all the files changed between %s and %s", base, in.Head)
+	}
+	return response, nil
+}
@@ -0,0 +1,309 @@
+package content
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleCommitFiles(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.CommitFilesResponse {
+	in := new(common.CommitFilesInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid commit files params: %v", err)
+		return common.CommitFilesResponse{Response: common.NewFailureResponse("Invalid commit files params", err)}
+	}
+	changes, err := validateCommitInput(in)
+	if err != nil {
+		logrus.Errorf("Invalid commit files params: %v", err)
+		return common.CommitFilesResponse{Response: common.NewFailureResponse("Invalid commit files params", err)}
+	}
+
+	var commitSha string
+	if gitclient.HasAPIAccess(provider, config) {
+		commitSha, err = commitFilesWithAPI(provider, config, in, changes)
+	} else {
+		commitSha, err = commitFilesWithGit(config, in, changes)
+	}
+	if err != nil {
+		logrus.Errorf("Failed committing files: %v", err)
+		return common.CommitFilesResponse{Response: common.NewFailureResponse("Failed committing files", err)}
+	}
+	return common.CommitFilesResponse{
+		Response:  common.NewSuccessResponse(),
+		CommitSha: commitSha,
+	}
+}
+
+// validateCommitInput checks the input and returns the changes with
+// their content decoded.
+func validateCommitInput(in *common.CommitFilesInput) ([]gitclient.FileChange, error) {
+	switch {
+	case in.Branch == "":
+		return nil, errors.New("branch is missing")
+	case in.Message == "":
+		return nil, errors.New("commit message is missing")
+	case len(in.Files) == 0:
+		return nil, errors.New("files are missing")
+	}
+	changes := []gitclient.FileChange{}
+	seen := map[string]bool{}
+	for _, file := range in.Files {
+		path := strings.Trim(file.Path, "/")
+		if path == "" {
+			return nil, errors.New("file path is missing")
+		}
+		if seen[path] {
+			return nil, fmt.Errorf("file %s is changed more than once", path)
+		}
+		seen[path] = true
+		switch file.Action {
+		case common.FileCreate, common.FileUpdate, common.FileDelete:
+		default:
+			return nil, fmt.Errorf("file action %s is not supported", file.Action)
+		}
+		data, err := decodeContent(file.Content, file.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, gitclient.FileChange{Action: file.Action, Path: path, Content: data, BlobSha: file.BlobSha})
+	}
+	return changes, nil
+}
+
+func commitFilesWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.CommitFilesInput, changes []gitclient.FileChange) (string, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	switch client.Driver {
+	case scm.DriverGithub:
+		logrus.Info("Committing files using the provider API")
+		return commitGithubFiles(ctx, client, repo, in, changes)
+	case scm.DriverGitlab:
+		logrus.Info("Committing files using the provider API")
+		return commitGitlabFiles(ctx, client, repo, in, changes)
+	}
+	// the generic API commits a single file at a time
+	logrus.Infof("Provider %s has no commit API, committing files using git", provider)
+	return commitFilesWithGit(config, in, changes)
+}
+
+func commitFilesWithGit(config *common.GitConnectorParams, in *common.CommitFilesInput, changes []gitclient.FileChange) (string, error) {
+	if in.Author == nil {
+		return "", errors.New("commit author is required to commit using git")
+	}
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return "", err
+	}
+	logrus.Info("Committing files using git")
+	author := &object.Signature{Name: in.Author.Name, Email: in.Author.Email, When: time.Now()}
+	commit, err := gitClient.CommitFiles(in.Branch, in.Message, author, changes)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
+type githubObject struct {
+	Sha  string `json:"sha"`
+	Type string `json:"type"`
+}
+
+// commitGithubFiles builds the commit through the git data API: a blob for
+// each file, a tree on top of the tree of the branch and a commit of that
+// tree. The branch is then moved to the commit without force, so that it
+// fails when the branch moved in the meantime.
+func commitGithubFiles(ctx context.Context, client *scm.Client, repo string, in *common.CommitFilesInput, changes []gitclient.FileChange) (string, error) {
+	ref := struct {
+		Object githubObject `json:"object"`
+	}{}
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/git/ref/heads/%s", repo, gitclient.EscapePath(in.Branch)), nil, &ref); err != nil {
+		return "", err
+	}
+	head := ref.Object.Sha
+	parent := struct {
+		Tree githubObject `json:"tree"`
+	}{}
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/git/commits/%s", repo, head), nil, &parent); err != nil {
+		return "", err
+	}
+
+	tree := &githubTreeLookup{ctx: ctx, client: client, repo: repo, root: parent.Tree.Sha, trees: map[string][]*githubTreeEntry{}}
+	entries := []map[string]interface{}{}
+	for _, change := range changes {
+		current, err := checkGithubFile(tree, change)
+		if err != nil {
+			return "", err
+		}
+		entry := map[string]interface{}{
+			"path": change.Path,
+			"mode": "100644",
+			"type": "blob",
+			// a nil sha deletes the file from the tree
+			"sha": nil,
+		}
+		if current != nil {
+			// executables and symlinks keep their mode
+			entry["mode"] = current.Mode
+		}
+		if change.Action != common.FileDelete {
+			blob := new(githubObject)
+			body := map[string]string{
+				"content":  base64.StdEncoding.EncodeToString(change.Content),
+				"encoding": common.EncodingBase64,
+			}
+			if _, err := gitclient.DoJSON(ctx, client, "POST", fmt.Sprintf("repos/%s/git/blobs", repo), body, blob); err != nil {
+				return "", err
+			}
+			entry["sha"] = blob.Sha
+		}
+		entries = append(entries, entry)
+	}
+
+	newTree := new(githubObject)
+	body := map[string]interface{}{"base_tree": parent.Tree.Sha, "tree": entries}
+	if _, err := gitclient.DoJSON(ctx, client, "POST", fmt.Sprintf("repos/%s/git/trees", repo), body, newTree); err != nil {
+		return "", err
+	}
+	commit := new(githubObject)
+	body = map[string]interface{}{"message": in.Message, "tree": newTree.Sha, "parents": []string{head}}
+	if in.Author != nil {
+		body["author"] = in.Author
+	}
+	if _, err := gitclient.DoJSON(ctx, client, "POST", fmt.Sprintf("repos/%s/git/commits", repo), body, commit); err != nil {
+		return "", err
+	}
+	body = map[string]interface{}{"sha": commit.Sha, "force": false}
+	if _, err := gitclient.DoJSON(ctx, client, "PATCH", fmt.Sprintf("repos/%s/git/refs/heads/%s", repo, gitclient.EscapePath(in.Branch)), body, nil); err != nil {
+		return "", fmt.Errorf("failed to move branch %s to commit %s: %w", in.Branch, commit.Sha, err)
+	}
+	return commit.Sha, nil
+}
+
+// githubTreeLookup looks up the entries of a tree one directory at a time, as
+// the recursive listing is truncated for large trees. Each directory is only
+// fetched once.
+type githubTreeLookup struct {
+	ctx    context.Context
+	client *scm.Client
+	repo   string
+	root   string
+	trees  map[string][]*githubTreeEntry
+}
+
+// find returns the entry at the path, or nil when there is none.
+func (t *githubTreeLookup) find(path string) (*githubTreeEntry, error) {
+	segments := strings.Split(path, "/")
+	sha := t.root
+	for i, segment := range segments {
+		entries, ok := t.trees[sha]
+		if !ok {
+			out := new(githubTree)
+			if _, err := gitclient.DoJSON(t.ctx, t.client, "GET", fmt.Sprintf("repos/%s/git/trees/%s", t.repo, sha), nil, out); err != nil {
+				return nil, err
+			}
+			entries = out.Tree
+			t.trees[sha] = entries
+		}
+		var entry *githubTreeEntry
+		for _, e := range entries {
+			if e.Path == segment {
+				entry = e
+				break
+			}
+		}
+		if entry == nil || i == len(segments)-1 {
+			return entry, nil
+		}
+		if entry.Type != "tree" {
+			return nil, nil
+		}
+		sha = entry.Sha
+	}
+	return nil, nil
+}
+
+// checkGithubFile verifies the file exists in the tree as the change
+// expects, with the expected blob, and returns its entry when it exists.
+func checkGithubFile(tree *githubTreeLookup, change gitclient.FileChange) (*githubTreeEntry, error) {
+	entry, err := tree.find(change.Path)
+	switch {
+	case err != nil:
+		return nil, err
+	case entry == nil:
+		if change.Action != common.FileCreate {
+			return nil, fmt.Errorf("file %s not found", change.Path)
+		}
+	case change.Action == common.FileCreate:
+		return nil, fmt.Errorf("file %s already exists", change.Path)
+	case entry.Type != "blob":
+		return nil, fmt.Errorf("%s is not a file", change.Path)
+	case change.BlobSha != "" && change.BlobSha != entry.Sha:
+		return nil, fmt.Errorf("file %s has changed, its blob is %s instead of %s", change.Path, entry.Sha, change.BlobSha)
+	}
+	return entry, nil
+}
+
+// commitGitlabFiles commits the files through the commits API, which
+// applies every action or none. Files with an expected blob are checked
+// first and their last commit passed along, for Gitlab to reject the commit
+// when they changed in the meantime.
+func commitGitlabFiles(ctx context.Context, client *scm.Client, repo string, in *common.CommitFilesInput, changes []gitclient.FileChange) (string, error) {
+	project := url.PathEscape(repo)
+	actions := []map[string]interface{}{}
+	for _, change := range changes {
+		action := map[string]interface{}{
+			"action":    change.Action,
+			"file_path": change.Path,
+		}
+		if change.Action != common.FileDelete {
+			action["content"] = base64.StdEncoding.EncodeToString(change.Content)
+			action["encoding"] = common.EncodingBase64
+		}
+		if change.BlobSha != "" {
+			current := struct {
+				BlobID       string `json:"blob_id"`
+				LastCommitID string `json:"last_commit_id"`
+			}{}
+			path := fmt.Sprintf("api/v4/projects/%s/repository/files/%s?ref=%s", project, url.PathEscape(change.Path), url.QueryEscape(in.Branch))
+			if _, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &current); err != nil {
+				return "", fmt.Errorf("failed to get file %s: %w", change.Path, err)
+			}
+			if current.BlobID != change.BlobSha {
+				return "", fmt.Errorf("file %s has changed, its blob is %s instead of %s", change.Path, current.BlobID, change.BlobSha)
+			}
+			action["last_commit_id"] = current.LastCommitID
+		}
+		actions = append(actions, action)
+	}
+
+	body := map[string]interface{}{
+		"branch":         in.Branch,
+		"commit_message": in.Message,
+		"actions":        actions,
+	}
+	if in.Author != nil {
+		body["author_name"] = in.Author.Name
+		body["author_email"] = in.Author.Email
+	}
+	out := struct {
+		ID string `json:"id"`
+	}{}
+	if _, err := gitclient.DoJSON(ctx, client, "POST", fmt.Sprintf("api/v4/projects/%s/repository/commits", project), body, &out); err != nil {
+		return "", err
+	}
+	return out.ID, nil
+}
@@ -0,0 +1,78 @@
+package content
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/harness/git-connector-cgi/common"
+)
+
+func TestHandleCommitFilesGithub(t *testing.T) {
+	var tree []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		route := r.Method + " " + r.URL.Path
+		switch route {
+		case "GET /repos/o/r/git/ref/heads/main":
+			w.Write([]byte(`{"object":{"sha":"head","type":"commit"}}`))
+		case "GET /repos/o/r/git/commits/head":
+			w.Write([]byte(`{"tree":{"sha":"root"}}`))
+		case "GET /repos/o/r/git/trees/root":
+			w.Write([]byte(`{"sha":"root","tree":[{"path":"pipelines","mode":"040000","type":"tree","sha":"dir"}]}`))
+		case "GET /repos/o/r/git/trees/dir":
+			w.Write([]byte(`{"sha":"dir","tree":[{"path":"build.sh","mode":"100755","type":"blob","sha":"old"}]}`))
+		case "POST /repos/o/r/git/blobs":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"sha":"blob"}`))
+		case "POST /repos/o/r/git/trees":
+			body := struct {
+				Tree []map[string]interface{} `json:"tree"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			tree = body.Tree
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"sha":"tree"}`))
+		case "POST /repos/o/r/git/commits":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"sha":"commit"}`))
+		case "PATCH /repos/o/r/git/refs/heads/main":
+			w.Write([]byte(`{}`))
+		default:
+			// Github answers missing contents with a message
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	config := &common.GitConnectorParams{
+		AuthType: common.AuthTypeHttp,
+		Repo:     "https://github.com/o/r.git",
+		HTTPAuth: &common.HTTPAuth{AuthMethod: common.HTTPAuthAnonymous},
+		APIAccess: &common.APIAccess{
+			AccessType: common.APIAccessToken,
+			Endpoint:   server.URL,
+			Token:      "token",
+		},
+	}
+	input := json.RawMessage(`{"branch":"main","message":"Save pipeline","files":[
+		{"action":"create","path":"pipelines/new.yaml","content":"pipeline: {}"},
+		{"action":"update","path":"pipelines/build.sh","content":"make","blob_sha":"old"}
+	]}`)
+	response := HandleCommitFiles(common.Github, config, input)
+	if response.Status != common.Success {
+		t.Fatalf("want success, got %s: %v", response.Status, response.Errors)
+	}
+	if response.CommitSha != "commit" {
+		t.Errorf("want commit commit, got %s", response.CommitSha)
+	}
+	modes := map[string]string{}
+	for _, entry := range tree {
+		modes[entry["path"].(string)] = entry["mode"].(string)
+	}
+	if modes["pipelines/new.yaml"] != "100644" || modes["pipelines/build.sh"] != "100755" {
+		t.Errorf("want new files as 100644 and existing modes kept, got %v", modes)
+	}
+}
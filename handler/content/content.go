@@ -0,0 +1,166 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+// binarySniffLength is how much of a file git looks at to decide
+// whether it is binary.
+const binarySniffLength = 8000
+
+func HandleGetFileContent(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.GetFileContentResponse {
+	in := new(common.GetFileContentInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid get file content params: %v", err)
+		return common.GetFileContentResponse{Response: common.NewFailureResponse("Invalid get file content params", err)}
+	}
+	in.Path = strings.Trim(in.Path, "/")
+	if in.Path == "" {
+		logrus.Error("File path is missing")
+		return common.GetFileContentResponse{Response: common.NewFailureResponse("Invalid get file content params", errors.New("file path is missing"))}
+	}
+
+	var file *common.FileContent
+	err := gitclient.WithAPIOrGit(provider, config, func() (err error) {
+		file, err = getFileContentWithAPI(provider, config, in)
+		return err
+	}, func() (err error) {
+		file, err = getFileContentWithGit(config, in)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("Failed getting file content: %v", err)
+		return common.GetFileContentResponse{Response: common.NewFailureResponse("Failed getting file content", err)}
+	}
+	return common.GetFileContentResponse{
+		Response: common.NewSuccessResponse(),
+		File:     file,
+	}
+}
+
+func getFileContentWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.GetFileContentInput) (*common.FileContent, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	if client.Contents == nil || client.Git == nil {
+		return nil, scm.ErrNotSupported
+	}
+	ctx := context.Background()
+	logrus.Info("Getting file content using the provider API")
+
+	commitSha, err := gitclient.ResolveCommit(ctx, client, repo, in.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if client.Driver == scm.DriverGithub {
+		return getGithubFileContent(ctx, client, repo, in.Path, commitSha)
+	}
+	content, _, err := client.Contents.Find(ctx, repo, in.Path, commitSha)
+	if err != nil {
+		return nil, err
+	}
+	blobSha := content.BlobID
+	if blobSha == "" {
+		// not every provider reports the blob, it is derived from the content
+		blobSha = plumbing.ComputeHash(plumbing.BlobObject, content.Data).String()
+	}
+	return newFileContent(in.Path, content.Data, blobSha, commitSha), nil
+}
+
+// githubContent is a file or blob as reported by the Github API.
+type githubContent struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+	Size     int64  `json:"size"`
+	Sha      string `json:"sha"`
+}
+
+// getGithubFileContent gets the file through the contents API. Files over
+// 1MB are reported there without their content, which is then read from the
+// blob API instead.
+func getGithubFileContent(ctx context.Context, client *scm.Client, repo, path, commitSha string) (*common.FileContent, error) {
+	out := new(githubContent)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/contents/%s?ref=%s", repo, gitclient.EscapePath(path), commitSha), nil, out); err != nil {
+		return nil, err
+	}
+	if out.Type != "file" {
+		return nil, fmt.Errorf("%s is not a file", path)
+	}
+	if out.Encoding == "none" || (out.Content == "" && out.Size > 0) {
+		logrus.Infof("File %s is too large for the contents API, getting it from the blob API", path)
+		if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/git/blobs/%s", repo, out.Sha), nil, out); err != nil {
+			return nil, err
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(out.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the content of file %s: %w", path, err)
+	}
+	return newFileContent(path, data, out.Sha, commitSha), nil
+}
+
+func getFileContentWithGit(config *common.GitConnectorParams, in *common.GetFileContentInput) (*common.FileContent, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Info("Getting file content using git")
+	_, commit, err := gitClient.Clone(in.Ref, 1)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(in.Path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return newFileContent(in.Path, data, file.Hash.String(), commit.Hash.String()), nil
+}
+
+func newFileContent(path string, data []byte, blobSha, commitSha string) *common.FileContent {
+	file := &common.FileContent{
+		Path:      path,
+		Content:   string(data),
+		Encoding:  common.EncodingUTF8,
+		BlobSha:   blobSha,
+		Size:      int64(len(data)),
+		CommitSha: commitSha,
+	}
+	if isBinary(data) {
+		file.Content = base64.StdEncoding.EncodeToString(data)
+		file.Encoding = common.EncodingBase64
+	}
+	return file
+}
+
+// isBinary uses the same heuristic as git, a file is binary when a NUL
+// byte appears in its first few thousand bytes.
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLength {
+		data = data[:binarySniffLength]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
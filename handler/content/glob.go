@@ -0,0 +1,44 @@
+package content
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether the slash separated name matches the pattern.
+// Besides the path.Match syntax, a ** segment matches any number of
+// directories, including none.
+func matchGlob(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// skip redundant ** segments, then try every split point
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
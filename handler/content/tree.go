@@ -0,0 +1,296 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleListTree(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ListTreeResponse {
+	in := new(common.ListTreeInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid list tree params: %v", err)
+		return common.ListTreeResponse{Response: common.NewFailureResponse("Invalid list tree params", err)}
+	}
+	in.Path = strings.Trim(in.Path, "/")
+	if _, err := path.Match(in.Glob, ""); err != nil {
+		logrus.Errorf("Invalid glob %s: %v", in.Glob, err)
+		return common.ListTreeResponse{Response: common.NewFailureResponse("Invalid list tree params", fmt.Errorf("invalid glob %s: %w", in.Glob, err))}
+	}
+	if in.MaxDepth < 0 {
+		logrus.Error("Max depth must not be negative")
+		return common.ListTreeResponse{Response: common.NewFailureResponse("Invalid list tree params", errors.New("max depth must not be negative"))}
+	}
+
+	// a depth of zero lists the whole tree below the path
+	maxDepth := 1
+	if in.Recursive {
+		maxDepth = in.MaxDepth
+	}
+
+	var (
+		commitSha string
+		entries   []common.TreeEntry
+	)
+	err := gitclient.WithAPIOrGit(provider, config, func() (err error) {
+		commitSha, entries, err = listTreeWithAPI(provider, config, in, maxDepth)
+		return err
+	}, func() (err error) {
+		commitSha, entries, err = listTreeWithGit(config, in, maxDepth)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("Failed listing tree: %v", err)
+		return common.ListTreeResponse{Response: common.NewFailureResponse("Failed listing tree", err)}
+	}
+
+	matched := []common.TreeEntry{}
+	for _, entry := range entries {
+		if matchGlob(in.Glob, entry.Path) {
+			matched = append(matched, entry)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Path < matched[j].Path
+	})
+	return common.ListTreeResponse{
+		Response:  common.NewSuccessResponse(),
+		CommitSha: commitSha,
+		Entries:   matched,
+	}
+}
+
+func listTreeWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.ListTreeInput, maxDepth int) (string, []common.TreeEntry, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return "", nil, err
+	}
+	if client.Contents == nil || client.Git == nil {
+		return "", nil, scm.ErrNotSupported
+	}
+	ctx := context.Background()
+	logrus.Info("Listing tree using the provider API")
+
+	commitSha, err := gitclient.ResolveCommit(ctx, client, repo, in.Ref)
+	if err != nil {
+		return "", nil, err
+	}
+	if client.Driver == scm.DriverGithub {
+		entries, truncated, err := listGithubTree(ctx, client, repo, commitSha, in.Path, maxDepth)
+		if err != nil {
+			return "", nil, err
+		}
+		if !truncated {
+			return commitSha, entries, nil
+		}
+		logrus.Warn("Github truncated the tree, listing directories one by one")
+	}
+	entries := []common.TreeEntry{}
+	if err := walkContents(ctx, client, repo, in.Path, commitSha, 1, maxDepth, &entries); err != nil {
+		return "", nil, err
+	}
+	return commitSha, entries, nil
+}
+
+type githubTree struct {
+	Sha       string             `json:"sha"`
+	Truncated bool               `json:"truncated"`
+	Tree      []*githubTreeEntry `json:"tree"`
+}
+
+type githubTreeEntry struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Sha  string `json:"sha"`
+	Size int64  `json:"size"`
+}
+
+// listGithubTree fetches the whole tree of the commit in a single request,
+// which unlike the contents API also reports the size of every file.
+func listGithubTree(ctx context.Context, client *scm.Client, repo, commitSha, dir string, maxDepth int) ([]common.TreeEntry, bool, error) {
+	out := new(githubTree)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/git/trees/%s?recursive=1", repo, commitSha), nil, out); err != nil {
+		return nil, false, err
+	}
+	entries := []common.TreeEntry{}
+	for _, item := range out.Tree {
+		depth, ok := relativeDepth(dir, item.Path)
+		if !ok || (maxDepth != 0 && depth > maxDepth) {
+			continue
+		}
+		entries = append(entries, common.TreeEntry{
+			Name: path.Base(item.Path),
+			Path: item.Path,
+			Type: githubEntryType(item.Mode),
+			Size: item.Size,
+			Sha:  item.Sha,
+		})
+	}
+	return entries, out.Truncated, nil
+}
+
+func githubEntryType(mode string) common.EntryType {
+	switch mode {
+	case "040000":
+		return common.EntryDir
+	case "160000":
+		return common.EntrySubmodule
+	case "120000":
+		return common.EntrySymlink
+	}
+	return common.EntryFile
+}
+
+// walkContents lists a directory with the contents API of the provider and
+// descends into its subdirectories until the max depth is reached. Azure
+// lists the whole subtree along with the directory itself and Bitbucket
+// Server lists every file below the directory relative to it, so those are
+// filtered by depth instead of descended into.
+func walkContents(ctx context.Context, client *scm.Client, repo, dir, ref string, depth, maxDepth int, entries *[]common.TreeEntry) error {
+	subtree := client.Driver == scm.DriverAzure || client.Driver == scm.DriverStash
+	opts := scm.ListOptions{Page: 1, Size: common.MaxPerPage}
+	for {
+		infos, response, err := client.Contents.List(ctx, repo, dir, ref, opts)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			entryPath := strings.Trim(info.Path, "/")
+			if client.Driver == scm.DriverStash {
+				entryPath = path.Join(dir, entryPath)
+			}
+			relative, ok := relativeDepth(dir, entryPath)
+			if entryPath == dir || !ok {
+				continue
+			}
+			entryDepth := depth + relative - 1
+			if maxDepth != 0 && entryDepth > maxDepth {
+				continue
+			}
+			sha := info.BlobID
+			if sha == "" {
+				sha = info.Sha
+			}
+			entry := common.TreeEntry{
+				Name: path.Base(entryPath),
+				Path: entryPath,
+				Type: contentEntryType(info.Kind),
+				Sha:  sha,
+			}
+			*entries = append(*entries, entry)
+			if !subtree && entry.Type == common.EntryDir && (maxDepth == 0 || entryDepth < maxDepth) {
+				if err := walkContents(ctx, client, repo, entry.Path, ref, depth+1, maxDepth, entries); err != nil {
+					return err
+				}
+			}
+		}
+		if response == nil || response.Page.Next <= opts.Page {
+			return nil
+		}
+		opts.Page = response.Page.Next
+	}
+}
+
+func contentEntryType(kind scm.ContentKind) common.EntryType {
+	switch kind {
+	case scm.ContentKindDirectory:
+		return common.EntryDir
+	case scm.ContentKindGitlink:
+		return common.EntrySubmodule
+	case scm.ContentKindSymlink:
+		return common.EntrySymlink
+	}
+	return common.EntryFile
+}
+
+func listTreeWithGit(config *common.GitConnectorParams, in *common.ListTreeInput, maxDepth int) (string, []common.TreeEntry, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return "", nil, err
+	}
+	logrus.Info("Listing tree using git")
+	repo, commit, err := gitClient.Clone(in.Ref, 1)
+	if err != nil {
+		return "", nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", nil, err
+	}
+	if in.Path != "" {
+		if tree, err = tree.Tree(in.Path); err != nil {
+			return "", nil, fmt.Errorf("failed to find directory %s: %w", in.Path, err)
+		}
+	}
+	entries := []common.TreeEntry{}
+	if err := walkGitTree(repo, tree, in.Path, 1, maxDepth, &entries); err != nil {
+		return "", nil, err
+	}
+	return commit.Hash.String(), entries, nil
+}
+
+// walkGitTree lists the entries of a git tree and descends into its
+// subtrees until the max depth is reached.
+func walkGitTree(repo *git.Repository, tree *object.Tree, dir string, depth, maxDepth int, entries *[]common.TreeEntry) error {
+	for _, e := range tree.Entries {
+		entry := common.TreeEntry{
+			Name: e.Name,
+			Path: path.Join(dir, e.Name),
+			Sha:  e.Hash.String(),
+		}
+		switch e.Mode {
+		case filemode.Dir:
+			entry.Type = common.EntryDir
+		case filemode.Submodule:
+			entry.Type = common.EntrySubmodule
+		case filemode.Symlink:
+			entry.Type = common.EntrySymlink
+		default:
+			entry.Type = common.EntryFile
+		}
+		if entry.Type == common.EntryFile || entry.Type == common.EntrySymlink {
+			blob, err := repo.BlobObject(e.Hash)
+			if err != nil {
+				return err
+			}
+			entry.Size = blob.Size
+		}
+		*entries = append(*entries, entry)
+
+		if entry.Type == common.EntryDir && (maxDepth == 0 || depth < maxDepth) {
+			subtree, err := tree.Tree(e.Name)
+			if err != nil {
+				return err
+			}
+			if err := walkGitTree(repo, subtree, entry.Path, depth+1, maxDepth, entries); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// relativeDepth returns how many levels below dir the entry is, or false
+// when the entry is not below dir.
+func relativeDepth(dir, entry string) (int, bool) {
+	if dir != "" {
+		if !strings.HasPrefix(entry, dir+"/") {
+			return 0, false
+		}
+		entry = strings.TrimPrefix(entry, dir+"/")
+	}
+	return strings.Count(entry, "/") + 1, true
+}
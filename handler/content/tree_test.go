@@ -0,0 +1,83 @@
+package content
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/harness/git-connector-cgi/common"
+)
+
+func TestHandleListTreeAzure(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "recursive",
+			input: `{"path":"pipelines","ref":"` + sha + `","recursive":true}`,
+			want:  []string{"pipelines/build.yaml", "pipelines/ci", "pipelines/ci/deploy.yaml"},
+		},
+		{
+			name:  "one level",
+			input: `{"path":"pipelines","ref":"` + sha + `"}`,
+			want:  []string{"pipelines/build.yaml", "pipelines/ci"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var lists int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/o/p/_apis/git/repositories/r/commits/"+sha):
+					w.Write([]byte(`{"commitId":"` + sha + `"}`))
+				case strings.HasSuffix(r.URL.Path, "/o/p/_apis/git/repositories/r/items"):
+					lists++
+					if lists > 1 {
+						t.Errorf("want a single listing of the subtree, got %s", r.URL)
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					// Azure lists the folder itself along with its whole subtree
+					w.Write([]byte(`{"count":4,"value":[
+						{"objectId":"d1","gitObjectType":"tree","path":"/pipelines","isFolder":true},
+						{"objectId":"b1","gitObjectType":"blob","path":"/pipelines/build.yaml"},
+						{"objectId":"d2","gitObjectType":"tree","path":"/pipelines/ci","isFolder":true},
+						{"objectId":"b2","gitObjectType":"blob","path":"/pipelines/ci/deploy.yaml"}
+					]}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			config := &common.GitConnectorParams{
+				AuthType: common.AuthTypeHttp,
+				Repo:     "https://dev.azure.com/o/p/_git/r",
+				HTTPAuth: &common.HTTPAuth{AuthMethod: common.HTTPAuthAnonymous},
+				APIAccess: &common.APIAccess{
+					AccessType: common.APIAccessToken,
+					Endpoint:   server.URL,
+					Token:      "token",
+				},
+			}
+			response := HandleListTree(common.AzureRepo, config, json.RawMessage(test.input))
+			if response.Status != common.Success {
+				t.Fatalf("want success, got %s: %v", response.Status, response.Errors)
+			}
+			var paths []string
+			for _, entry := range response.Entries {
+				paths = append(paths, entry.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(test.want, ",") {
+				t.Errorf("want entries %v, got %v", test.want, paths)
+			}
+		})
+	}
+}
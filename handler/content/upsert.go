@@ -0,0 +1,256 @@
+package content
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleUpsertFile(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.UpsertFileResponse {
+	in := new(common.UpsertFileInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid upsert file params: %v", err)
+		return common.UpsertFileResponse{Response: common.NewFailureResponse("Invalid upsert file params", err)}
+	}
+	data, err := validateUpsertInput(in)
+	if err != nil {
+		logrus.Errorf("Invalid upsert file params: %v", err)
+		return common.UpsertFileResponse{Response: common.NewFailureResponse("Invalid upsert file params", err)}
+	}
+
+	var commitSha string
+	err = gitclient.WithAPIOrGit(provider, config, func() (err error) {
+		commitSha, err = upsertFileWithAPI(provider, config, in, data)
+		return err
+	}, func() (err error) {
+		commitSha, err = upsertFileWithGit(config, in, data)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("Failed upserting file %s: %v", in.Path, err)
+		return common.UpsertFileResponse{Response: common.NewFailureResponse("Failed upserting file", err)}
+	}
+	return common.UpsertFileResponse{
+		Response:  common.NewSuccessResponse(),
+		CommitSha: commitSha,
+		// the blob only depends on the content, whichever way it was committed
+		BlobSha: plumbing.ComputeHash(plumbing.BlobObject, data).String(),
+	}
+}
+
+// validateUpsertInput checks the input and returns the decoded content.
+func validateUpsertInput(in *common.UpsertFileInput) ([]byte, error) {
+	in.Path = strings.Trim(in.Path, "/")
+	switch {
+	case in.Path == "":
+		return nil, errors.New("file path is missing")
+	case in.Branch == "":
+		return nil, errors.New("branch is missing")
+	case in.Message == "":
+		return nil, errors.New("commit message is missing")
+	}
+	return decodeContent(in.Content, in.Encoding)
+}
+
+func decodeContent(content, encoding string) ([]byte, error) {
+	switch encoding {
+	case "", common.EncodingUTF8:
+		return []byte(content), nil
+	case common.EncodingBase64:
+		return base64.StdEncoding.DecodeString(content)
+	}
+	return nil, fmt.Errorf("encoding %s is not supported", encoding)
+}
+
+func upsertFileWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.UpsertFileInput, data []byte) (string, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return "", err
+	}
+	if client.Contents == nil || client.Git == nil {
+		return "", scm.ErrNotSupported
+	}
+	ctx := context.Background()
+	logrus.Info("Upserting file using the provider API")
+	switch client.Driver {
+	case scm.DriverGithub:
+		return upsertGithubFile(ctx, client, repo, in, data)
+	case scm.DriverGitlab:
+		return upsertGitlabFile(ctx, client, repo, in, data)
+	}
+
+	// the generic API commits on top of the head it is given, which is
+	// also how the commit it made is found afterwards
+	head, err := gitclient.ResolveCommit(ctx, client, repo, in.Branch)
+	if err != nil {
+		return "", err
+	}
+	params := &scm.ContentParams{
+		Ref:     head,
+		Branch:  in.Branch,
+		Message: in.Message,
+		Data:    data,
+		Sha:     head,
+	}
+	if in.Author != nil {
+		params.Signature = scm.Signature{Name: in.Author.Name, Email: in.Author.Email}
+	}
+	current, res, err := client.Contents.Find(ctx, repo, in.Path, head)
+	switch {
+	case gitclient.IsNotFound(res, err):
+		if in.BlobSha != "" {
+			return "", fmt.Errorf("file %s not found", in.Path)
+		}
+		_, err = client.Contents.Create(ctx, repo, in.Path, params)
+	case err != nil:
+		return "", err
+	default:
+		// some providers report the blob, others only the commit of the file
+		if in.BlobSha != "" && in.BlobSha != current.BlobID && in.BlobSha != current.Sha &&
+			in.BlobSha != plumbing.ComputeHash(plumbing.BlobObject, current.Data).String() {
+			return "", fmt.Errorf("file %s has changed since blob %s", in.Path, in.BlobSha)
+		}
+		params.BlobID = current.BlobID
+		_, err = client.Contents.Update(ctx, repo, in.Path, params)
+	}
+	if err != nil {
+		return "", err
+	}
+	return committedOnto(ctx, client, repo, in.Branch, head), nil
+}
+
+// committedOnto returns the head of the branch when it was committed on top
+// of the given head, as the generic API does not report the commit it made.
+// No commit is returned when someone else committed to the branch meanwhile.
+func committedOnto(ctx context.Context, client *scm.Client, repo, branch, head string) string {
+	commits, _, err := client.Git.ListCommits(ctx, repo, scm.CommitListOptions{Ref: branch, Page: 1, Size: 2})
+	if err != nil {
+		logrus.Warnf("Failed finding the commit made on branch %s: %v", branch, err)
+		return ""
+	}
+	if len(commits) < 2 || commits[1].Sha != head {
+		logrus.Warnf("Branch %s moved on after the commit was made, it is not reported", branch)
+		return ""
+	}
+	return commits[0].Sha
+}
+
+func upsertFileWithGit(config *common.GitConnectorParams, in *common.UpsertFileInput, data []byte) (string, error) {
+	if in.Author == nil {
+		return "", errors.New("commit author is required to commit using git")
+	}
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return "", err
+	}
+	logrus.Info("Upserting file using git")
+	author := &object.Signature{Name: in.Author.Name, Email: in.Author.Email, When: time.Now()}
+	commit, err := gitClient.CommitFiles(in.Branch, in.Message, author, []gitclient.FileChange{
+		{Path: in.Path, Content: data, BlobSha: in.BlobSha},
+	})
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
+// upsertGithubFile puts the file through the contents API, which takes the
+// previous blob to update a file and rejects the update when it changed.
+func upsertGithubFile(ctx context.Context, client *scm.Client, repo string, in *common.UpsertFileInput, data []byte) (string, error) {
+	body := map[string]interface{}{
+		"message": in.Message,
+		"content": base64.StdEncoding.EncodeToString(data),
+		"branch":  in.Branch,
+	}
+	if in.Author != nil {
+		body["author"] = in.Author
+	}
+	sha := in.BlobSha
+	if sha == "" {
+		// without a blob the file is overwritten when it exists
+		current := struct {
+			Sha string `json:"sha"`
+		}{}
+		path := fmt.Sprintf("repos/%s/contents/%s?ref=%s", repo, gitclient.EscapePath(in.Path), url.QueryEscape(in.Branch))
+		if res, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &current); err != nil && !gitclient.IsNotFound(res, err) {
+			return "", err
+		}
+		sha = current.Sha
+	}
+	if sha != "" {
+		body["sha"] = sha
+	}
+
+	out := struct {
+		Commit struct {
+			Sha string `json:"sha"`
+		} `json:"commit"`
+	}{}
+	if _, err := gitclient.DoJSON(ctx, client, "PUT", fmt.Sprintf("repos/%s/contents/%s", repo, gitclient.EscapePath(in.Path)), body, &out); err != nil {
+		return "", err
+	}
+	return out.Commit.Sha, nil
+}
+
+// upsertGitlabFile commits the file through the commits API, which reports
+// the commit it made. Gitlab checks for concurrent changes by the last commit
+// of the file rather than its blob, so the blob is checked first and its
+// commit passed along with the update.
+func upsertGitlabFile(ctx context.Context, client *scm.Client, repo string, in *common.UpsertFileInput, data []byte) (string, error) {
+	project := url.PathEscape(repo)
+	current := struct {
+		BlobID       string `json:"blob_id"`
+		LastCommitID string `json:"last_commit_id"`
+	}{}
+	path := fmt.Sprintf("api/v4/projects/%s/repository/files/%s?ref=%s", project, url.PathEscape(in.Path), url.QueryEscape(in.Branch))
+	res, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &current)
+
+	action := map[string]interface{}{
+		"action":    "update",
+		"file_path": in.Path,
+		"content":   base64.StdEncoding.EncodeToString(data),
+		"encoding":  "base64",
+	}
+	switch {
+	case gitclient.IsNotFound(res, err):
+		if in.BlobSha != "" {
+			return "", fmt.Errorf("file %s not found", in.Path)
+		}
+		action["action"] = "create"
+	case err != nil:
+		return "", err
+	case in.BlobSha != "" && in.BlobSha != current.BlobID:
+		return "", fmt.Errorf("file %s has changed, its blob is %s instead of %s", in.Path, current.BlobID, in.BlobSha)
+	default:
+		action["last_commit_id"] = current.LastCommitID
+	}
+
+	body := map[string]interface{}{
+		"branch":         in.Branch,
+		"commit_message": in.Message,
+		"actions":        []interface{}{action},
+	}
+	if in.Author != nil {
+		body["author_name"] = in.Author.Name
+		body["author_email"] = in.Author.Email
+	}
+	out := struct {
+		ID string `json:"id"`
+	}{}
+	if _, err := gitclient.DoJSON(ctx, client, "POST", fmt.Sprintf("api/v4/projects/%s/repository/commits", project), body, &out); err != nil {
+		return "", err
+	}
+	return out.ID, nil
+}
@@ -0,0 +1,154 @@
+package content
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/harness/git-connector-cgi/common"
+)
+
+func TestHandleUpsertFileCreate(t *testing.T) {
+	tests := []struct {
+		provider common.Provider
+		// notFound is the body the provider answers with for a missing file
+		notFound string
+		// create matches the request creating the file and answers it
+		create func(t *testing.T, r *http.Request, body map[string]interface{}) string
+	}{
+		{
+			provider: common.Github,
+			notFound: `{"message":"Not Found","documentation_url":"https://docs.github.com/rest/repos/contents#get-repository-content","status":"404"}`,
+			create: func(t *testing.T, r *http.Request, body map[string]interface{}) string {
+				if r.Method != http.MethodPut || !strings.HasSuffix(r.URL.Path, "/repos/o/r/contents/pipelines/new.yaml") {
+					return ""
+				}
+				if _, ok := body["sha"]; ok {
+					t.Errorf("want no blob for a new file, got %v", body["sha"])
+				}
+				return `{"commit":{"sha":"c0ffee"}}`
+			},
+		},
+		{
+			provider: common.GitLab,
+			notFound: `{"message":"404 File Not Found"}`,
+			create: func(t *testing.T, r *http.Request, body map[string]interface{}) string {
+				if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/repository/commits") {
+					return ""
+				}
+				actions, _ := body["actions"].([]interface{})
+				if len(actions) != 1 || actions[0].(map[string]interface{})["action"] != "create" {
+					t.Errorf("want a single create action, got %v", body["actions"])
+				}
+				return `{"id":"c0ffee"}`
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(string(test.provider), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodGet {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(test.notFound))
+					return
+				}
+				body := map[string]interface{}{}
+				json.NewDecoder(r.Body).Decode(&body)
+				if out := test.create(t, r, body); out != "" {
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(out))
+					return
+				}
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+				w.WriteHeader(http.StatusBadRequest)
+			}))
+			defer server.Close()
+
+			config := &common.GitConnectorParams{
+				AuthType: common.AuthTypeHttp,
+				Repo:     "https://example.com/o/r.git",
+				HTTPAuth: &common.HTTPAuth{AuthMethod: common.HTTPAuthAnonymous},
+				APIAccess: &common.APIAccess{
+					AccessType: common.APIAccessToken,
+					Endpoint:   server.URL,
+					Token:      "token",
+				},
+			}
+			input := json.RawMessage(`{"path":"pipelines/new.yaml","branch":"main","content":"pipeline: {}","message":"Add pipeline"}`)
+			response := HandleUpsertFile(test.provider, config, input)
+			if response.Status != common.Success {
+				t.Fatalf("want success, got %s: %v", response.Status, response.Errors)
+			}
+			if response.CommitSha != "c0ffee" {
+				t.Errorf("want commit c0ffee, got %s", response.CommitSha)
+			}
+		})
+	}
+}
+
+func TestHandleUpsertFileGenericCommit(t *testing.T) {
+	tests := []struct {
+		name string
+		// commits is the history of the branch after the write
+		commits string
+		want    string
+	}{
+		{
+			name:    "committed on the head",
+			commits: `{"values":[{"id":"c0ffee"},{"id":"head"}],"isLastPage":true}`,
+			want:    "c0ffee",
+		},
+		{
+			name:    "branch moved on",
+			commits: `{"values":[{"id":"other"},{"id":"c0ffee"},{"id":"head"}],"isLastPage":true}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				route := r.Method + " " + r.URL.Path
+				switch route {
+				case "GET /rest/api/1.0/projects/o/repos/r/commits/main":
+					w.Write([]byte(`{"id":"head"}`))
+				case "GET /rest/api/1.0/projects/o/repos/r/raw/pipelines/new.yaml":
+					if r.URL.Query().Get("at") != "head" {
+						t.Errorf("want the file read at the head, got %s", r.URL)
+					}
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"errors":[{"message":"The path \"pipelines/new.yaml\" does not exist at revision \"head\""}]}`))
+				case "PUT /rest/api/1.0/projects/o/repos/r/browse/pipelines/new.yaml":
+					w.Write([]byte(`{}`))
+				case "GET /rest/api/1.0/projects/o/repos/r/commits":
+					w.Write([]byte(test.commits))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			}))
+			defer server.Close()
+
+			config := &common.GitConnectorParams{
+				AuthType: common.AuthTypeHttp,
+				Repo:     "https://example.com/scm/o/r.git",
+				HTTPAuth: &common.HTTPAuth{AuthMethod: common.HTTPAuthAnonymous},
+				APIAccess: &common.APIAccess{
+					AccessType: common.APIAccessToken,
+					Endpoint:   server.URL,
+					Token:      "token",
+				},
+			}
+			input := json.RawMessage(`{"path":"pipelines/new.yaml","branch":"main","content":"pipeline: {}","message":"Add pipeline"}`)
+			response := HandleUpsertFile(common.BitbucketServer, config, input)
+			if response.Status != common.Success {
+				t.Fatalf("want success, got %s: %v", response.Status, response.Errors)
+			}
+			if response.CommitSha != test.want {
+				t.Errorf("want commit %q, got %q", test.want, response.CommitSha)
+			}
+		})
+	}
+}
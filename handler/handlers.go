@@ -8,10 +8,92 @@ import (
 	"strings"
 
 	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/harness/git-connector-cgi/handler/branch"
+	"github.com/harness/git-connector-cgi/handler/commit"
+	"github.com/harness/git-connector-cgi/handler/content"
+	"github.com/harness/git-connector-cgi/handler/pullrequest"
+	"github.com/harness/git-connector-cgi/handler/ref"
+	"github.com/harness/git-connector-cgi/handler/repository"
+	"github.com/harness/git-connector-cgi/handler/tag"
 	"github.com/harness/git-connector-cgi/handler/validate"
+	"github.com/harness/git-connector-cgi/handler/webhook"
 	"github.com/sirupsen/logrus"
 )
 
+const validateOperation = "validate"
+
+// operations maps the supported operations, other than validate, to their
+// handlers.
+var operations = map[string]func(request *common.RequestData) interface{}{
+	"list_branches": func(request *common.RequestData) interface{} {
+		return branch.HandleListBranches(request.Provider, request.Params, request.Input)
+	},
+	"create_branch": func(request *common.RequestData) interface{} {
+		return branch.HandleCreateBranch(request.Provider, request.Params, request.Input)
+	},
+	"delete_branch": func(request *common.RequestData) interface{} {
+		return branch.HandleDeleteBranch(request.Provider, request.Params, request.Input)
+	},
+	"list_tags": func(request *common.RequestData) interface{} {
+		return tag.HandleListTags(request.Provider, request.Params, request.Input)
+	},
+	"get_file_content": func(request *common.RequestData) interface{} {
+		return content.HandleGetFileContent(request.Provider, request.Params, request.Input)
+	},
+	"upsert_file": func(request *common.RequestData) interface{} {
+		return content.HandleUpsertFile(request.Provider, request.Params, request.Input)
+	},
+	"commit_files": func(request *common.RequestData) interface{} {
+		return content.HandleCommitFiles(request.Provider, request.Params, request.Input)
+	},
+	"list_tree": func(request *common.RequestData) interface{} {
+		return content.HandleListTree(request.Provider, request.Params, request.Input)
+	},
+	"list_commits": func(request *common.RequestData) interface{} {
+		return commit.HandleListCommits(request.Provider, request.Params, request.Input)
+	},
+	"get_commit": func(request *common.RequestData) interface{} {
+		return commit.HandleGetCommit(request.Provider, request.Params, request.Input)
+	},
+	"compare_refs": func(request *common.RequestData) interface{} {
+		return commit.HandleCompareRefs(request.Provider, request.Params, request.Input)
+	},
+	"resolve_ref": func(request *common.RequestData) interface{} {
+		return ref.HandleResolveRef(request.Provider, request.Params, request.Input)
+	},
+	"create_pull_request": func(request *common.RequestData) interface{} {
+		return pullrequest.HandleCreatePullRequest(request.Provider, request.Params, request.Input)
+	},
+	"list_pull_requests": func(request *common.RequestData) interface{} {
+		return pullrequest.HandleListPullRequests(request.Provider, request.Params, request.Input)
+	},
+	"get_pull_request": func(request *common.RequestData) interface{} {
+		return pullrequest.HandleGetPullRequest(request.Provider, request.Params, request.Input)
+	},
+	"list_pull_request_files": func(request *common.RequestData) interface{} {
+		return pullrequest.HandleListPullRequestFiles(request.Provider, request.Params, request.Input)
+	},
+	"list_repositories": func(request *common.RequestData) interface{} {
+		return repository.HandleListRepositories(request.Provider, request.Params, request.Input)
+	},
+	"get_repository": func(request *common.RequestData) interface{} {
+		return repository.HandleGetRepository(request.Provider, request.Params)
+	},
+	"create_webhook": func(request *common.RequestData) interface{} {
+		return webhook.HandleCreateWebhook(request.Provider, request.Params, request.Input)
+	},
+	"list_webhooks": func(request *common.RequestData) interface{} {
+		return webhook.HandleListWebhooks(request.Provider, request.Params, request.Input)
+	},
+	"update_webhook": func(request *common.RequestData) interface{} {
+		return webhook.HandleUpdateWebhook(request.Provider, request.Params, request.Input)
+	},
+	"delete_webhook": func(request *common.RequestData) interface{} {
+		return webhook.HandleDeleteWebhook(request.Provider, request.Params, request.Input)
+	},
+}
+
 func HandleRequest(w http.ResponseWriter, r *http.Request) {
 	request := new(common.RequestData)
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -27,18 +109,49 @@ func HandleRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	operation := strings.ToLower(request.Operation)
-
-	var result interface{}
-
-	switch operation {
-	case "validate":
-		result = validate.HandleValidate(request.Provider, request.Params)
-	default:
+	handle, ok := operations[operation]
+	if !ok && operation != validateOperation {
 		logrus.Errorf("The specified action %s is not supported", operation)
 		SendErrorResponse(w, errors.New("invalid action"), fmt.Sprintf("The specified action %s is not supported", operation), http.StatusBadRequest)
 		return
 	}
+	provider, endpoint := detectProvider(request)
+
+	var result interface{}
+	if operation == validateOperation {
+		response := validate.HandleValidate(request.Provider, request.Params)
+		response.Provider, response.APIEndpoint = provider, endpoint
+		result = response
+	} else {
+		result = handle(request)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
+
+// detectProvider infers the provider from the repository URL when the
+// connector does not specify one or specifies Git. The request is updated
+// with the detected provider and API endpoint, which are also returned so
+// they can be reported back to the caller.
+func detectProvider(request *common.RequestData) (common.Provider, string) {
+	if request.Provider != "" && request.Provider != common.Git {
+		return "", ""
+	}
+	var proxy string
+	if request.Params.APIAccess != nil {
+		proxy = request.Params.APIAccess.ProxyURL
+	}
+	provider, endpoint := gitclient.DetectProvider(request.Params.Repo, proxy)
+	if provider == "" {
+		// fall back to the generic git provider which needs no API
+		request.Provider = common.GenericGit
+		return "", ""
+	}
+	logrus.Infof("Detected provider %s with API endpoint %s from repository URL", provider, endpoint)
+	request.Provider = provider
+	if request.Params.APIAccess != nil && request.Params.APIAccess.Endpoint == "" {
+		request.Params.APIAccess.Endpoint = endpoint
+	}
+	return provider, endpoint
+}
@@ -0,0 +1,163 @@
+package pullrequest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+)
+
+type githubPullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	Draft   bool   `json:"draft"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Head           githubBranch `json:"head"`
+	Base           githubBranch `json:"base"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	MergedAt       *time.Time   `json:"merged_at"`
+	Mergeable      *bool        `json:"mergeable"`
+	MergeableState string       `json:"mergeable_state"`
+}
+
+type githubBranch struct {
+	Ref string `json:"ref"`
+	Sha string `json:"sha"`
+}
+
+// createGithubPullRequest opens the pull request, then requests the reviews
+// and adds the labels, which Github does not accept on creation. The pull
+// request is returned along with the errors of these later calls, as it
+// exists by then.
+func createGithubPullRequest(ctx context.Context, client *scm.Client, repo string, in *common.CreatePullRequestInput) (*scm.PullRequest, error) {
+	body := map[string]interface{}{
+		"title": in.Title,
+		"body":  in.Body,
+		"head":  in.Source,
+		"base":  in.Target,
+		"draft": in.Draft,
+	}
+	out := new(githubPullRequest)
+	if _, err := gitclient.DoJSON(ctx, client, "POST", fmt.Sprintf("repos/%s/pulls", repo), body, out); err != nil {
+		return nil, err
+	}
+	pr := &scm.PullRequest{Number: out.Number, Link: out.HTMLURL}
+
+	var errs []error
+	if len(in.Reviewers) > 0 {
+		path := fmt.Sprintf("repos/%s/pulls/%d/requested_reviewers", repo, out.Number)
+		if _, err := gitclient.DoJSON(ctx, client, "POST", path, map[string][]string{"reviewers": in.Reviewers}, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to request reviews: %w", err))
+		}
+	}
+	if len(in.Labels) > 0 {
+		path := fmt.Sprintf("repos/%s/issues/%d/labels", repo, out.Number)
+		if _, err := gitclient.DoJSON(ctx, client, "POST", path, map[string][]string{"labels": in.Labels}, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add labels: %w", err))
+		}
+	}
+	return pr, errors.Join(errs...)
+}
+
+func listGithubPullRequests(ctx context.Context, client *scm.Client, repo string, in *common.ListPullRequestsInput) ([]common.PullRequest, int, error) {
+	params := url.Values{}
+	params.Set("page", fmt.Sprint(in.Page))
+	params.Set("per_page", fmt.Sprint(in.PerPage))
+	switch in.State {
+	case common.PullRequestMerged:
+		// merged pull requests are listed as closed
+		params.Set("state", string(common.PullRequestClosed))
+	default:
+		params.Set("state", string(in.State))
+	}
+	if in.Head != "" {
+		// the head branch is filtered on as {owner}:{branch}
+		head := in.Head
+		if !strings.Contains(head, ":") {
+			head = strings.SplitN(repo, "/", 2)[0] + ":" + head
+		}
+		params.Set("head", head)
+	}
+	if in.Base != "" {
+		params.Set("base", in.Base)
+	}
+
+	out := []*githubPullRequest{}
+	response, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/pulls?%s", repo, params.Encode()), nil, &out)
+	if err != nil {
+		return nil, 0, err
+	}
+	prs := []common.PullRequest{}
+	for _, pr := range out {
+		converted := convertGithubPullRequest(pr)
+		// the closed pull requests Github lists include the merged ones
+		if in.State != common.PullRequestAll && converted.State != in.State {
+			continue
+		}
+		// the mergeability is not computed for listed pull requests
+		converted.Mergeable = nil
+		converted.MergeableState = ""
+		prs = append(prs, *converted)
+	}
+	return prs, response.Page.Next, nil
+}
+
+func getGithubPullRequest(ctx context.Context, client *scm.Client, repo string, number int) (*common.PullRequest, error) {
+	out := new(githubPullRequest)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/pulls/%d", repo, number), nil, out); err != nil {
+		return nil, err
+	}
+	return convertGithubPullRequest(out), nil
+}
+
+func listGithubPullRequestFiles(ctx context.Context, client *scm.Client, repo string, in *common.ListPullRequestFilesInput) ([]common.ChangedFile, int, error) {
+	out := []*gitclient.GithubFile{}
+	path := fmt.Sprintf("repos/%s/pulls/%d/files?page=%d&per_page=%d", repo, in.Number, in.Page, in.PerPage)
+	response, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &out)
+	if err != nil {
+		return nil, 0, err
+	}
+	return gitclient.GithubChangedFiles(out), response.Page.Next, nil
+}
+
+func convertGithubPullRequest(from *githubPullRequest) *common.PullRequest {
+	pr := &common.PullRequest{
+		Number:         from.Number,
+		Title:          from.Title,
+		Body:           from.Body,
+		State:          common.PullRequestState(from.State),
+		Draft:          from.Draft,
+		Source:         from.Head.Ref,
+		Target:         from.Base.Ref,
+		HeadSha:        from.Head.Sha,
+		BaseSha:        from.Base.Sha,
+		Author:         from.User.Login,
+		URL:            from.HTMLURL,
+		Created:        from.CreatedAt,
+		Updated:        from.UpdatedAt,
+		Mergeable:      from.Mergeable,
+		MergeableState: from.MergeableState,
+	}
+	if from.MergedAt != nil {
+		pr.State = common.PullRequestMerged
+	}
+	for _, label := range from.Labels {
+		pr.Labels = append(pr.Labels, label.Name)
+	}
+	return pr
+}
@@ -0,0 +1,187 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+)
+
+// gitlabDraftPrefix marks a merge request as a draft.
+const gitlabDraftPrefix = "Draft: "
+
+type gitlabUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+type gitlabMergeRequest struct {
+	IID          int      `json:"iid"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	State        string   `json:"state"`
+	Draft        bool     `json:"draft"`
+	SourceBranch string   `json:"source_branch"`
+	TargetBranch string   `json:"target_branch"`
+	Sha          string   `json:"sha"`
+	WebURL       string   `json:"web_url"`
+	Labels       []string `json:"labels"`
+	Author       struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	DiffRefs *struct {
+		BaseSha string `json:"base_sha"`
+		HeadSha string `json:"head_sha"`
+	} `json:"diff_refs"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	MergeStatus         string    `json:"merge_status"`
+	DetailedMergeStatus string    `json:"detailed_merge_status"`
+}
+
+func createGitlabMergeRequest(ctx context.Context, client *scm.Client, repo string, in *common.CreatePullRequestInput) (*scm.PullRequest, error) {
+	title := in.Title
+	if in.Draft && !strings.HasPrefix(title, gitlabDraftPrefix) {
+		title = gitlabDraftPrefix + title
+	}
+	reviewers, err := gitlabUserIDs(ctx, client, in.Reviewers)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{
+		"title":         title,
+		"description":   in.Body,
+		"source_branch": in.Source,
+		"target_branch": in.Target,
+	}
+	if len(reviewers) > 0 {
+		body["reviewer_ids"] = reviewers
+	}
+	if len(in.Labels) > 0 {
+		body["labels"] = strings.Join(in.Labels, ",")
+	}
+
+	out := new(gitlabMergeRequest)
+	path := fmt.Sprintf("api/v4/projects/%s/merge_requests", url.PathEscape(repo))
+	if _, err := gitclient.DoJSON(ctx, client, "POST", path, body, out); err != nil {
+		return nil, err
+	}
+	return &scm.PullRequest{Number: out.IID, Link: out.WebURL}, nil
+}
+
+// gitlabUserIDs looks up the ids of the users, since Gitlab only accepts
+// reviewers by id.
+func gitlabUserIDs(ctx context.Context, client *scm.Client, usernames []string) ([]int, error) {
+	ids := []int{}
+	for _, username := range usernames {
+		users := []*gitlabUser{}
+		path := fmt.Sprintf("api/v4/users?username=%s", url.QueryEscape(username))
+		if _, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &users); err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("user %s not found", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+func listGitlabMergeRequests(ctx context.Context, client *scm.Client, repo string, in *common.ListPullRequestsInput) ([]common.PullRequest, int, error) {
+	params := url.Values{}
+	params.Set("page", fmt.Sprint(in.Page))
+	params.Set("per_page", fmt.Sprint(in.PerPage))
+	switch in.State {
+	case common.PullRequestOpen:
+		params.Set("state", "opened")
+	default:
+		params.Set("state", string(in.State))
+	}
+	if in.Head != "" {
+		params.Set("source_branch", in.Head)
+	}
+	if in.Base != "" {
+		params.Set("target_branch", in.Base)
+	}
+
+	out := []*gitlabMergeRequest{}
+	path := fmt.Sprintf("api/v4/projects/%s/merge_requests?%s", url.PathEscape(repo), params.Encode())
+	response, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &out)
+	if err != nil {
+		return nil, 0, err
+	}
+	prs := []common.PullRequest{}
+	for _, mr := range out {
+		converted := convertGitlabMergeRequest(mr)
+		// the merge status of listed merge requests may be stale
+		converted.Mergeable = nil
+		converted.MergeableState = ""
+		prs = append(prs, *converted)
+	}
+	return prs, response.Page.Next, nil
+}
+
+func getGitlabMergeRequest(ctx context.Context, client *scm.Client, repo string, number int) (*common.PullRequest, error) {
+	out := new(gitlabMergeRequest)
+	path := fmt.Sprintf("api/v4/projects/%s/merge_requests/%d", url.PathEscape(repo), number)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", path, nil, out); err != nil {
+		return nil, err
+	}
+	return convertGitlabMergeRequest(out), nil
+}
+
+func listGitlabMergeRequestFiles(ctx context.Context, client *scm.Client, repo string, in *common.ListPullRequestFilesInput) ([]common.ChangedFile, int, error) {
+	out := []*gitclient.GitlabDiff{}
+	path := fmt.Sprintf("api/v4/projects/%s/merge_requests/%d/diffs?page=%d&per_page=%d", url.PathEscape(repo), in.Number, in.Page, in.PerPage)
+	response, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &out)
+	if err != nil {
+		return nil, 0, err
+	}
+	return gitclient.GitlabChangedFiles(out), response.Page.Next, nil
+}
+
+func convertGitlabMergeRequest(from *gitlabMergeRequest) *common.PullRequest {
+	pr := &common.PullRequest{
+		Number:         from.IID,
+		Title:          from.Title,
+		Body:           from.Description,
+		State:          common.PullRequestState(from.State),
+		Draft:          from.Draft,
+		Source:         from.SourceBranch,
+		Target:         from.TargetBranch,
+		HeadSha:        from.Sha,
+		Author:         from.Author.Username,
+		URL:            from.WebURL,
+		Labels:         from.Labels,
+		Created:        from.CreatedAt,
+		Updated:        from.UpdatedAt,
+		MergeableState: from.DetailedMergeStatus,
+	}
+	switch from.State {
+	case "opened", "locked":
+		pr.State = common.PullRequestOpen
+	}
+	if from.DiffRefs != nil {
+		pr.BaseSha = from.DiffRefs.BaseSha
+	}
+	if pr.MergeableState == "" {
+		// detailed_merge_status was added in Gitlab 15.6
+		pr.MergeableState = from.MergeStatus
+	}
+	switch pr.MergeableState {
+	case "mergeable", "can_be_merged":
+		mergeable := true
+		pr.Mergeable = &mergeable
+	case "unchecked", "checking", "preparing", "approvals_syncing", "cannot_be_merged_recheck", "":
+		// still being computed
+	default:
+		mergeable := false
+		pr.Mergeable = &mergeable
+	}
+	return pr
+}
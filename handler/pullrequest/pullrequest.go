@@ -0,0 +1,307 @@
+package pullrequest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func HandleCreatePullRequest(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.CreatePullRequestResponse {
+	in := new(common.CreatePullRequestInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid create pull request params: %v", err)
+		return common.CreatePullRequestResponse{Response: common.NewFailureResponse("Invalid create pull request params", err)}
+	}
+	if err := validateCreateInput(in); err != nil {
+		logrus.Errorf("Invalid create pull request params: %v", err)
+		return common.CreatePullRequestResponse{Response: common.NewFailureResponse("Invalid create pull request params", err)}
+	}
+
+	pr, err := createPullRequest(provider, config, in)
+	if pr == nil {
+		logrus.Errorf("Failed creating pull request: %v", err)
+		return common.CreatePullRequestResponse{Response: common.NewFailureResponse("Failed creating pull request", err)}
+	}
+	if err != nil {
+		// the pull request exists, it is reported for the caller not to
+		// create it again
+		logrus.Warnf("Created pull request %d, failed completing it: %v", pr.Number, err)
+		return common.CreatePullRequestResponse{
+			Response: common.NewPartialResponse("Created pull request without all its reviewers and labels", err),
+			Number:   pr.Number,
+			URL:      pr.Link,
+		}
+	}
+	logrus.Infof("Created pull request %d", pr.Number)
+	return common.CreatePullRequestResponse{
+		Response: common.NewSuccessResponse(),
+		Number:   pr.Number,
+		URL:      pr.Link,
+	}
+}
+
+func validateCreateInput(in *common.CreatePullRequestInput) error {
+	if in.Source == "" {
+		return errors.New("source branch is required")
+	}
+	if in.Title == "" {
+		return errors.New("title is required")
+	}
+	return nil
+}
+
+// getPullRequestClient returns the API client of the provider, as pull
+// requests cannot be managed through git.
+func getPullRequestClient(provider common.Provider, config *common.GitConnectorParams) (*scm.Client, string, error) {
+	if !gitclient.HasAPIAccess(provider, config) {
+		return nil, "", status.Errorf(codes.FailedPrecondition, "API access is required to manage pull requests")
+	}
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, "", err
+	}
+	if client.PullRequests == nil {
+		return nil, "", status.Errorf(codes.Unimplemented, "%s pull requests not implemented yet", provider)
+	}
+	return client, repo, nil
+}
+
+// createPullRequest returns the pull request once it is created, along
+// with any error in adding its reviewers and labels afterwards.
+func createPullRequest(provider common.Provider, config *common.GitConnectorParams, in *common.CreatePullRequestInput) (*scm.PullRequest, error) {
+	client, repo, err := getPullRequestClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if in.Target, err = gitclient.DefaultRef(ctx, client, repo, in.Target); err != nil {
+		return nil, err
+	}
+	logrus.Infof("Creating pull request from %s to %s", in.Source, in.Target)
+	switch client.Driver {
+	case scm.DriverGithub:
+		return createGithubPullRequest(ctx, client, repo, in)
+	case scm.DriverGitlab:
+		return createGitlabMergeRequest(ctx, client, repo, in)
+	}
+
+	// the generic API has no options beyond the title and body, fail
+	// before creating a pull request that is missing any of them
+	if in.Draft || len(in.Reviewers) > 0 || len(in.Labels) > 0 {
+		return nil, status.Errorf(codes.Unimplemented, "%s draft pull requests, reviewers and labels not implemented yet", provider)
+	}
+	pr, _, err := client.PullRequests.Create(ctx, repo, &scm.PullRequestInput{
+		Title:  in.Title,
+		Body:   in.Body,
+		Source: in.Source,
+		Target: in.Target,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func HandleListPullRequests(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ListPullRequestsResponse {
+	in := new(common.ListPullRequestsInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid list pull requests params: %v", err)
+		return common.ListPullRequestsResponse{Response: common.NewFailureResponse("Invalid list pull requests params", err)}
+	}
+	in.Normalize()
+	switch in.State {
+	case "":
+		in.State = common.PullRequestOpen
+	case common.PullRequestOpen, common.PullRequestClosed, common.PullRequestMerged, common.PullRequestAll:
+	default:
+		err := fmt.Errorf("pull request state %s is not supported", in.State)
+		logrus.Errorf("Invalid list pull requests params: %v", err)
+		return common.ListPullRequestsResponse{Response: common.NewFailureResponse("Invalid list pull requests params", err)}
+	}
+
+	prs, nextPage, err := listPullRequests(provider, config, in)
+	if err != nil {
+		logrus.Errorf("Failed listing pull requests: %v", err)
+		return common.ListPullRequestsResponse{Response: common.NewFailureResponse("Failed listing pull requests", err)}
+	}
+	return common.ListPullRequestsResponse{
+		Response:     common.NewSuccessResponse(),
+		PullRequests: prs,
+		NextPage:     nextPage,
+	}
+}
+
+func HandleGetPullRequest(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.GetPullRequestResponse {
+	in := new(common.GetPullRequestInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid get pull request params: %v", err)
+		return common.GetPullRequestResponse{Response: common.NewFailureResponse("Invalid get pull request params", err)}
+	}
+	if in.Number < 1 {
+		err := errors.New("pull request number is required")
+		logrus.Errorf("Invalid get pull request params: %v", err)
+		return common.GetPullRequestResponse{Response: common.NewFailureResponse("Invalid get pull request params", err)}
+	}
+
+	pr, err := getPullRequest(provider, config, in.Number)
+	if err != nil {
+		logrus.Errorf("Failed getting pull request %d: %v", in.Number, err)
+		return common.GetPullRequestResponse{Response: common.NewFailureResponse("Failed getting pull request", err)}
+	}
+	return common.GetPullRequestResponse{
+		Response:    common.NewSuccessResponse(),
+		PullRequest: pr,
+	}
+}
+
+func HandleListPullRequestFiles(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ListPullRequestFilesResponse {
+	in := new(common.ListPullRequestFilesInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid list pull request files params: %v", err)
+		return common.ListPullRequestFilesResponse{Response: common.NewFailureResponse("Invalid list pull request files params", err)}
+	}
+	in.Normalize()
+	if in.Number < 1 {
+		err := errors.New("pull request number is required")
+		logrus.Errorf("Invalid list pull request files params: %v", err)
+		return common.ListPullRequestFilesResponse{Response: common.NewFailureResponse("Invalid list pull request files params", err)}
+	}
+
+	files, nextPage, err := listPullRequestFiles(provider, config, in)
+	if err != nil {
+		logrus.Errorf("Failed listing pull request files: %v", err)
+		return common.ListPullRequestFilesResponse{Response: common.NewFailureResponse("Failed listing pull request files", err)}
+	}
+	return common.ListPullRequestFilesResponse{
+		Response: common.NewSuccessResponse(),
+		Files:    files,
+		NextPage: nextPage,
+	}
+}
+
+func listPullRequests(provider common.Provider, config *common.GitConnectorParams, in *common.ListPullRequestsInput) ([]common.PullRequest, int, error) {
+	client, repo, err := getPullRequestClient(provider, config)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx := context.Background()
+	logrus.Info("Listing pull requests using the provider API")
+	switch client.Driver {
+	case scm.DriverGithub:
+		return listGithubPullRequests(ctx, client, repo, in)
+	case scm.DriverGitlab:
+		return listGitlabMergeRequests(ctx, client, repo, in)
+	}
+
+	opts := scm.PullRequestListOptions{
+		Page:   in.Page,
+		Size:   in.PerPage,
+		Open:   in.State == common.PullRequestOpen || in.State == common.PullRequestAll,
+		Closed: in.State != common.PullRequestOpen,
+	}
+	out, response, err := client.PullRequests.List(ctx, repo, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	prs := []common.PullRequest{}
+	for _, pr := range out {
+		// the generic API filters neither on the branches nor on merged
+		converted := convertPullRequest(pr)
+		if matchPullRequest(converted, in) {
+			prs = append(prs, *converted)
+		}
+	}
+	return prs, response.Page.Next, nil
+}
+
+func getPullRequest(provider common.Provider, config *common.GitConnectorParams, number int) (*common.PullRequest, error) {
+	client, repo, err := getPullRequestClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	logrus.Info("Getting pull request using the provider API")
+	switch client.Driver {
+	case scm.DriverGithub:
+		return getGithubPullRequest(ctx, client, repo, number)
+	case scm.DriverGitlab:
+		return getGitlabMergeRequest(ctx, client, repo, number)
+	}
+
+	// the generic API does not report whether the pull request is mergeable
+	pr, _, err := client.PullRequests.Find(ctx, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	return convertPullRequest(pr), nil
+}
+
+func listPullRequestFiles(provider common.Provider, config *common.GitConnectorParams, in *common.ListPullRequestFilesInput) ([]common.ChangedFile, int, error) {
+	client, repo, err := getPullRequestClient(provider, config)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx := context.Background()
+	logrus.Info("Listing pull request files using the provider API")
+	switch client.Driver {
+	case scm.DriverGithub:
+		return listGithubPullRequestFiles(ctx, client, repo, in)
+	case scm.DriverGitlab:
+		return listGitlabMergeRequestFiles(ctx, client, repo, in)
+	}
+
+	changes, response, err := client.PullRequests.ListChanges(ctx, repo, in.Number, scm.ListOptions{Page: in.Page, Size: in.PerPage})
+	if err != nil {
+		return nil, 0, err
+	}
+	files := []common.ChangedFile{}
+	for _, change := range changes {
+		files = append(files, gitclient.ScmChangedFile(change))
+	}
+	return files, response.Page.Next, nil
+}
+
+func convertPullRequest(from *scm.PullRequest) *common.PullRequest {
+	pr := &common.PullRequest{
+		Number:  from.Number,
+		Title:   from.Title,
+		Body:    from.Body,
+		State:   common.PullRequestOpen,
+		Draft:   from.Draft,
+		Source:  from.Source,
+		Target:  from.Target,
+		HeadSha: from.Sha,
+		BaseSha: from.Base.Sha,
+		Author:  from.Author.Login,
+		URL:     from.Link,
+		Created: from.Created,
+		Updated: from.Updated,
+	}
+	switch {
+	case from.Merged:
+		pr.State = common.PullRequestMerged
+	case from.Closed:
+		pr.State = common.PullRequestClosed
+	}
+	for _, label := range from.Labels {
+		pr.Labels = append(pr.Labels, label.Name)
+	}
+	return pr
+}
+
+// matchPullRequest reports whether the pull request matches the state and
+// branches the pull requests are listed by.
+func matchPullRequest(pr *common.PullRequest, in *common.ListPullRequestsInput) bool {
+	if in.State != common.PullRequestAll && pr.State != in.State {
+		return false
+	}
+	return (in.Head == "" || pr.Source == in.Head) && (in.Base == "" || pr.Target == in.Base)
+}
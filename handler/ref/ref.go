@@ -0,0 +1,153 @@
+package ref
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleResolveRef(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ResolveRefResponse {
+	in := new(common.ResolveRefInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid resolve ref params: %v", err)
+		return common.ResolveRefResponse{Response: common.NewFailureResponse("Invalid resolve ref params", err)}
+	}
+
+	response, err := resolveRef(provider, config, strings.TrimSpace(in.Ref))
+	if err != nil {
+		logrus.Errorf("Failed resolving ref %s: %v", in.Ref, err)
+		return common.ResolveRefResponse{Response: common.NewFailureResponse("Failed resolving ref", err)}
+	}
+	response.Response = common.NewSuccessResponse()
+	return *response
+}
+
+// resolveRef resolves a branch, tag or commit SHA to the full SHA of the
+// commit it points to. An empty ref resolves to the default branch.
+func resolveRef(provider common.Provider, config *common.GitConnectorParams, ref string) (*common.ResolveRefResponse, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Info("Resolving ref using the ref advertisement")
+	refs, err := gitClient.ListRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &common.ResolveRefResponse{
+		DefaultBranch: gitclient.HeadTarget(refs).Short(),
+	}
+	hashes := map[plumbing.ReferenceName]string{}
+	for _, r := range refs {
+		if r.Type() == plumbing.HashReference {
+			hashes[r.Name()] = r.Hash().String()
+		}
+	}
+
+	if ref == "" || ref == plumbing.HEAD.String() {
+		if response.DefaultBranch == "" {
+			return nil, fmt.Errorf("remote does not advertise its default branch")
+		}
+		ref = response.DefaultBranch
+	}
+	if name := gitclient.FindRef(refs, ref); name != "" {
+		response.Ref = name.String()
+		response.RefType = common.RefOther
+		response.Sha = hashes[name]
+		switch {
+		case name.IsBranch():
+			response.RefType = common.RefBranch
+		case name.IsTag():
+			response.RefType = common.RefTag
+			// annotated tags are advertised along with the commit they point to
+			if commit, ok := hashes[name+gitclient.PeeledSuffix]; ok {
+				response.Sha = commit
+			}
+		}
+		return response, nil
+	}
+
+	if !isHex(ref) {
+		return nil, fmt.Errorf("ref %s not found", ref)
+	}
+	response.RefType = common.RefCommit
+	// the SHA may be the head of one of the refs, saving a lookup
+	if response.Sha, err = matchCommit(hashes, ref); err != nil || response.Sha != "" {
+		return response, err
+	}
+	if response.Sha, err = resolveCommit(provider, config, gitClient, ref); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// matchCommit returns the commit the refs point to that the SHA is a prefix
+// of, or an empty string when there is none. The objects of annotated tags
+// are skipped in favor of the commits they point to.
+func matchCommit(hashes map[plumbing.ReferenceName]string, sha string) (string, error) {
+	sha = strings.ToLower(sha)
+	matches := map[string]bool{}
+	for name, hash := range hashes {
+		if _, ok := hashes[name+gitclient.PeeledSuffix]; ok {
+			continue
+		}
+		if strings.HasPrefix(hash, sha) {
+			matches[hash] = true
+		}
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("short SHA %s is ambiguous", sha)
+	}
+	for hash := range matches {
+		return hash, nil
+	}
+	return "", nil
+}
+
+// resolveCommit expands a commit SHA that is not the head of any ref,
+// through the provider API when there is access to it or else by cloning
+// the repository.
+func resolveCommit(provider common.Provider, config *common.GitConnectorParams, gitClient *gitclient.GitClient, sha string) (string, error) {
+	var commitSha string
+	err := gitclient.WithAPIOrGit(provider, config, func() error {
+		client, repo, err := gitclient.GetRepoClient(provider, config)
+		if err != nil {
+			return err
+		}
+		if client.Git == nil {
+			return scm.ErrNotSupported
+		}
+		logrus.Info("Resolving commit using the provider API")
+		commitSha, err = gitclient.ResolveCommit(context.Background(), client, repo, sha)
+		return err
+	}, func() error {
+		logrus.Info("Resolving commit using git")
+		_, commit, err := gitClient.Clone(sha, 0)
+		if err != nil {
+			return err
+		}
+		commitSha = commit.Hash.String()
+		return nil
+	})
+	return commitSha, err
+}
+
+func isHex(s string) bool {
+	if len(s) < 4 || len(s) > 64 {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
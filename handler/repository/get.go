@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleGetRepository(provider common.Provider, config *common.GitConnectorParams) common.GetRepositoryResponse {
+	var (
+		repo *common.Repository
+		err  error
+	)
+	if gitclient.HasAPIAccess(provider, config) {
+		repo, err = getRepositoryWithAPI(provider, config)
+	} else {
+		repo, err = getRepositoryWithGit(config)
+	}
+	if err != nil {
+		logrus.Errorf("Failed getting repository: %v", err)
+		return common.GetRepositoryResponse{Response: common.NewFailureResponse("Failed getting repository", err)}
+	}
+	return common.GetRepositoryResponse{
+		Response:   common.NewSuccessResponse(),
+		Repository: repo,
+	}
+}
+
+func getRepositoryWithAPI(provider common.Provider, config *common.GitConnectorParams) (*common.Repository, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	logrus.Info("Getting repository using the provider API")
+	switch client.Driver {
+	case scm.DriverGithub:
+		return getGithubRepository(ctx, client, repo)
+	case scm.DriverGitlab:
+		return getGitlabRepository(ctx, client, repo)
+	}
+
+	// the generic API reports neither the fork parent nor the size
+	out, _, err := client.Repositories.Find(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if out.Perm == nil {
+		if out.Perm, _, err = client.Repositories.FindPerms(ctx, repo); err != nil {
+			logrus.Warnf("Failed to get repository permissions: %v", err)
+		}
+	}
+	return convertRepository(out), nil
+}
+
+// getRepositoryWithGit reports what the ref advertisement tells about the
+// repository, which is little more than its default branch.
+func getRepositoryWithGit(config *common.GitConnectorParams) (*common.Repository, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Info("Getting repository using git")
+	refs, err := gitClient.ListRefs()
+	if err != nil {
+		return nil, err
+	}
+	repo := &common.Repository{
+		DefaultBranch: gitclient.HeadTarget(refs).Short(),
+		// the repository could be listed, so it can at least be pulled
+		Permissions: &common.Permissions{Pull: true},
+	}
+	if slug, err := gitclient.RepoSlug(common.GenericGit, config.Repo); err == nil {
+		repo.FullName = slug
+		namespace, name := path.Split(slug)
+		repo.Namespace, repo.Name = strings.TrimSuffix(namespace, "/"), name
+	}
+	if config.AuthType == common.AuthTypeSsh {
+		repo.SSHURL = config.Repo
+	} else {
+		repo.CloneURL = config.Repo
+	}
+	return repo, nil
+}
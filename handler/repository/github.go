@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+)
+
+type githubRepository struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	Visibility    string `json:"visibility"`
+	Private       bool   `json:"private"`
+	Archived      bool   `json:"archived"`
+	Disabled      bool   `json:"disabled"`
+	Fork          bool   `json:"fork"`
+	CloneURL      string `json:"clone_url"`
+	SSHURL        string `json:"ssh_url"`
+	HTMLURL       string `json:"html_url"`
+	// Size is in kilobytes.
+	Size  int64 `json:"size"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Parent *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	Permissions *struct {
+		Admin bool `json:"admin"`
+		Push  bool `json:"push"`
+		Pull  bool `json:"pull"`
+	} `json:"permissions"`
+}
+
+func getGithubRepository(ctx context.Context, client *scm.Client, repo string) (*common.Repository, error) {
+	out := new(githubRepository)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s", repo), nil, out); err != nil {
+		return nil, err
+	}
+	return convertGithubRepository(out), nil
+}
+
+// listGithubUserRepositories lists the repositories of a user, which go-scm
+// does not cover as it only lists those of organizations.
+func listGithubUserRepositories(ctx context.Context, client *scm.Client, in *common.ListRepositoriesInput) ([]common.Repository, int, error) {
+	out := []*githubRepository{}
+	path := fmt.Sprintf("users/%s/repos?page=%d&per_page=%d", url.PathEscape(in.Namespace), in.Page, in.PerPage)
+	response, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &out)
+	if err != nil {
+		return nil, 0, err
+	}
+	repos := []common.Repository{}
+	for _, repo := range out {
+		if common.MatchFilter(repo.Name, in.Filter) {
+			repos = append(repos, *convertGithubRepository(repo))
+		}
+	}
+	return repos, response.Page.Next, nil
+}
+
+func convertGithubRepository(out *githubRepository) *common.Repository {
+	result := &common.Repository{
+		Name:          out.Name,
+		Namespace:     out.Owner.Login,
+		FullName:      out.FullName,
+		DefaultBranch: out.DefaultBranch,
+		Visibility:    out.Visibility,
+		Private:       out.Private,
+		Archived:      out.Archived,
+		CloneURL:      out.CloneURL,
+		SSHURL:        out.SSHURL,
+		URL:           out.HTMLURL,
+		Disabled:      out.Disabled,
+		Fork:          out.Fork,
+		Size:          out.Size * 1024,
+	}
+	if out.Parent != nil {
+		result.Parent = out.Parent.FullName
+	}
+	if out.Permissions != nil {
+		result.Permissions = &common.Permissions{Pull: out.Permissions.Pull, Push: out.Permissions.Push, Admin: out.Permissions.Admin}
+	}
+	if result.Visibility == "" {
+		// Github Enterprise Server before 3.1 only reports private
+		result.Visibility = "public"
+		if out.Private {
+			result.Visibility = "private"
+		}
+	}
+	return result
+}
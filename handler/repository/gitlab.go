@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+)
+
+// Gitlab access levels, see
+// https://docs.gitlab.com/ee/api/members.html#roles
+const (
+	gitlabReporterAccess   = 20
+	gitlabDeveloperAccess  = 30
+	gitlabMaintainerAccess = 40
+)
+
+type gitlabProject struct {
+	Path              string `json:"path"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	Visibility        string `json:"visibility"`
+	Archived          bool   `json:"archived"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+	SSHURLToRepo      string `json:"ssh_url_to_repo"`
+	WebURL            string `json:"web_url"`
+	Namespace         struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+	ForkedFromProject *struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"forked_from_project"`
+	Statistics *struct {
+		RepositorySize int64 `json:"repository_size"`
+	} `json:"statistics"`
+	Permissions struct {
+		ProjectAccess *gitlabAccess `json:"project_access"`
+		GroupAccess   *gitlabAccess `json:"group_access"`
+	} `json:"permissions"`
+}
+
+type gitlabAccess struct {
+	AccessLevel int `json:"access_level"`
+}
+
+func getGitlabRepository(ctx context.Context, client *scm.Client, repo string) (*common.Repository, error) {
+	out := new(gitlabProject)
+	path := fmt.Sprintf("api/v4/projects/%s?statistics=true", url.PathEscape(repo))
+	if _, err := gitclient.DoJSON(ctx, client, "GET", path, nil, out); err != nil {
+		return nil, err
+	}
+	result := &common.Repository{
+		Name:          out.Path,
+		Namespace:     out.Namespace.FullPath,
+		FullName:      out.PathWithNamespace,
+		DefaultBranch: out.DefaultBranch,
+		Visibility:    out.Visibility,
+		Private:       out.Visibility == "private",
+		Archived:      out.Archived,
+		CloneURL:      out.HTTPURLToRepo,
+		SSHURL:        out.SSHURLToRepo,
+		URL:           out.WebURL,
+		Fork:          out.ForkedFromProject != nil,
+	}
+	if out.ForkedFromProject != nil {
+		result.Parent = out.ForkedFromProject.PathWithNamespace
+	}
+	// the statistics are only reported to reporters and above
+	if out.Statistics != nil {
+		result.Size = out.Statistics.RepositorySize
+	}
+
+	// the access level is the highest of the project and group membership
+	level := 0
+	for _, access := range []*gitlabAccess{out.Permissions.ProjectAccess, out.Permissions.GroupAccess} {
+		if access != nil && access.AccessLevel > level {
+			level = access.AccessLevel
+		}
+	}
+	result.Permissions = &common.Permissions{
+		Pull:  level >= gitlabReporterAccess || out.Visibility != "private",
+		Push:  level >= gitlabDeveloperAccess,
+		Admin: level >= gitlabMaintainerAccess,
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/drone/go-scm/scm/driver/github"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func HandleListRepositories(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ListRepositoriesResponse {
+	in := new(common.ListRepositoriesInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid list repositories params: %v", err)
+		return common.ListRepositoriesResponse{Response: common.NewFailureResponse("Invalid list repositories params", err)}
+	}
+	in.Normalize()
+
+	repos, nextPage, err := listRepositories(provider, config, in)
+	if err != nil {
+		logrus.Errorf("Failed listing repositories: %v", err)
+		return common.ListRepositoriesResponse{Response: common.NewFailureResponse("Failed listing repositories", err)}
+	}
+	return common.ListRepositoriesResponse{
+		Response:     common.NewSuccessResponse(),
+		Repositories: repos,
+		NextPage:     nextPage,
+	}
+}
+
+// listRepositories lists the repositories of the namespace, or else every
+// repository the credentials can access. Github Apps can only access the
+// repositories of their installation.
+func listRepositories(provider common.Provider, config *common.GitConnectorParams, in *common.ListRepositoriesInput) ([]common.Repository, int, error) {
+	if !gitclient.HasAPIAccess(provider, config) {
+		return nil, 0, status.Errorf(codes.FailedPrecondition, "API access is required to list repositories")
+	}
+	client, err := gitclient.GetGitClient(provider, config.Repo, config.APIAccess)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx := context.Background()
+	opts := scm.ListOptions{Page: in.Page, Size: in.PerPage}
+
+	var (
+		out      []*scm.Repository
+		response *scm.Response
+		// set when the listed repositories are not limited to the namespace
+		filterNamespace bool
+	)
+	switch {
+	case config.APIAccess.AccessType == common.APIAccessGithubApp && client.Driver == scm.DriverGithub:
+		logrus.Info("Listing repositories of the Github App installation")
+		out, response, err = client.Repositories.(*github.RepositoryService).ListByInstallation(ctx, opts)
+		filterNamespace = in.Namespace != ""
+	case in.Filter != "" && canSearch(provider, client.Driver, in.Namespace):
+		logrus.Infof("Searching repositories matching %s", in.Filter)
+		term := scm.RepoSearchTerm{RepoName: in.Filter, User: in.Namespace}
+		if client.Driver == scm.DriverGithub {
+			// the search query is passed on without being escaped
+			term.RepoName = url.QueryEscape(in.Filter)
+		}
+		out, response, err = client.Repositories.ListV2(ctx, scm.RepoListOptions{ListOptions: opts, RepoSearchTerm: term})
+	case in.Namespace != "":
+		logrus.Infof("Listing repositories of namespace %s", in.Namespace)
+		out, response, err = client.Repositories.ListNamespace(ctx, in.Namespace, opts)
+		if client.Driver == scm.DriverGithub && gitclient.IsNotFound(response, err) {
+			// only organizations are namespaces to go-scm on Github
+			logrus.Infof("Namespace %s is not an organization, listing repositories of the user", in.Namespace)
+			return listGithubUserRepositories(ctx, client, in)
+		}
+	default:
+		logrus.Info("Listing repositories using the provider API")
+		out, response, err = client.Repositories.List(ctx, opts)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	repos := []common.Repository{}
+	for _, repo := range out {
+		if filterNamespace && !strings.EqualFold(repo.Namespace, in.Namespace) {
+			continue
+		}
+		// the provider search can be looser than the filter, when there is one
+		if common.MatchFilter(repo.Name, in.Filter) {
+			repos = append(repos, *convertRepository(repo))
+		}
+	}
+	return repos, response.Page.Next, nil
+}
+
+// canSearch reports whether the provider can search the repositories by name
+// within the requested scope. Github searches the repositories of an owner,
+// the others search every repository the credentials can access.
+func canSearch(provider common.Provider, driver scm.Driver, namespace string) bool {
+	switch {
+	case driver == scm.DriverGithub:
+		return namespace != ""
+	case driver == scm.DriverGitlab, driver == scm.DriverBitbucket, driver == scm.DriverStash, provider == common.Gerrit:
+		return namespace == ""
+	}
+	return false
+}
+
+func convertRepository(from *scm.Repository) *common.Repository {
+	repo := &common.Repository{
+		Name:          from.Name,
+		Namespace:     from.Namespace,
+		FullName:      scm.Join(from.Namespace, from.Name),
+		DefaultBranch: from.Branch,
+		Visibility:    from.Visibility.String(),
+		Private:       from.Private,
+		Archived:      from.Archived,
+		CloneURL:      from.Clone,
+		SSHURL:        from.CloneSSH,
+		URL:           from.Link,
+	}
+	if from.Perm != nil {
+		repo.Permissions = &common.Permissions{Pull: from.Perm.Pull, Push: from.Perm.Push, Admin: from.Perm.Admin}
+	}
+	if from.Visibility == scm.VisibilityUndefined {
+		// not every provider reports the visibility beyond being private
+		repo.Visibility = "public"
+		if from.Private {
+			repo.Visibility = "private"
+		}
+	}
+	return repo
+}
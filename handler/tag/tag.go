@@ -0,0 +1,216 @@
+package tag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+)
+
+func HandleListTags(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ListTagsResponse {
+	in := new(common.ListTagsInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid list tags params: %v", err)
+		return common.ListTagsResponse{Response: common.NewFailureResponse("Invalid list tags params", err)}
+	}
+	in.Normalize()
+
+	var (
+		tags     []common.Tag
+		nextPage int
+	)
+	err := gitclient.WithAPIOrGit(provider, config, func() (err error) {
+		tags, nextPage, err = listTagsWithAPI(provider, config, in)
+		return err
+	}, func() (err error) {
+		tags, nextPage, err = listTagsWithGit(config, in)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("Failed listing tags: %v", err)
+		return common.ListTagsResponse{Response: common.NewFailureResponse("Failed listing tags", err)}
+	}
+	return common.ListTagsResponse{
+		Response: common.NewSuccessResponse(),
+		Tags:     tags,
+		NextPage: nextPage,
+	}
+}
+
+func listTagsWithAPI(provider common.Provider, config *common.GitConnectorParams, in *common.ListTagsInput) ([]common.Tag, int, error) {
+	client, repo, err := gitclient.GetRepoClient(provider, config)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx := context.Background()
+	logrus.Info("Listing tags using the provider API")
+	switch client.Driver {
+	case scm.DriverGithub:
+		return listGithubTags(ctx, client, repo, in)
+	case scm.DriverGitlab:
+		return listGitlabTags(ctx, client, repo, in)
+	}
+	if client.Git == nil {
+		return nil, 0, scm.ErrNotSupported
+	}
+
+	// the generic API only knows the commit each tag points to
+	refs, response, err := client.Git.ListTags(ctx, repo, scm.ListOptions{Page: in.Page, Size: in.PerPage})
+	if err != nil {
+		return nil, 0, err
+	}
+	tags := []common.Tag{}
+	for _, ref := range refs {
+		if common.MatchFilter(ref.Name, in.Filter) {
+			tags = append(tags, common.Tag{Name: ref.Name, Sha: ref.Sha, CommitSha: ref.Sha})
+		}
+	}
+	return tags, response.Page.Next, nil
+}
+
+type githubRef struct {
+	Ref    string `json:"ref"`
+	Object struct {
+		Type string `json:"type"`
+		Sha  string `json:"sha"`
+	} `json:"object"`
+}
+
+type githubTag struct {
+	Sha    string `json:"sha"`
+	Tagger struct {
+		Name  string    `json:"name"`
+		Email string    `json:"email"`
+		Date  time.Time `json:"date"`
+	} `json:"tagger"`
+	Object struct {
+		Type string `json:"type"`
+		Sha  string `json:"sha"`
+	} `json:"object"`
+}
+
+// listGithubTags lists the tag refs, which carry the tag object SHA, and
+// looks up the tag objects of the annotated tags in the page to peel them.
+func listGithubTags(ctx context.Context, client *scm.Client, repo string, in *common.ListTagsInput) ([]common.Tag, int, error) {
+	refs := []*githubRef{}
+	path := fmt.Sprintf("repos/%s/git/matching-refs/tags/", repo)
+	if _, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &refs); err != nil {
+		return nil, 0, err
+	}
+
+	tags := []common.Tag{}
+	for _, ref := range refs {
+		name := strings.TrimPrefix(ref.Ref, "refs/tags/")
+		if !common.MatchFilter(name, in.Filter) {
+			continue
+		}
+		tags = append(tags, common.Tag{Name: name, Sha: ref.Object.Sha, CommitSha: ref.Object.Sha, Annotated: ref.Object.Type == "tag"})
+	}
+	start, end, nextPage := in.PageInput.Bounds(len(tags))
+	tags = tags[start:end]
+
+	for i := range tags {
+		if !tags[i].Annotated {
+			continue
+		}
+		out := new(githubTag)
+		if _, err := gitclient.DoJSON(ctx, client, "GET", fmt.Sprintf("repos/%s/git/tags/%s", repo, tags[i].Sha), nil, out); err != nil {
+			return nil, 0, err
+		}
+		tags[i].CommitSha = out.Object.Sha
+		tags[i].Tagger = &common.Signature{Name: out.Tagger.Name, Email: out.Tagger.Email, Date: out.Tagger.Date}
+		tags[i].Date = &out.Tagger.Date
+	}
+	return tags, nextPage, nil
+}
+
+type gitlabTag struct {
+	Name      string     `json:"name"`
+	Target    string     `json:"target"`
+	Message   string     `json:"message"`
+	CreatedAt *time.Time `json:"created_at"`
+	Commit    struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// listGitlabTags lists the tags with their target, which is the tag object
+// SHA for annotated tags, and the commit they point to. Gitlab reports the
+// creation date of annotated tags but not their tagger.
+func listGitlabTags(ctx context.Context, client *scm.Client, repo string, in *common.ListTagsInput) ([]common.Tag, int, error) {
+	params := url.Values{}
+	params.Set("page", fmt.Sprint(in.Page))
+	params.Set("per_page", fmt.Sprint(in.PerPage))
+	if in.Filter != "" {
+		params.Set("search", in.Filter)
+	}
+	path := fmt.Sprintf("api/v4/projects/%s/repository/tags?%s", url.PathEscape(repo), params.Encode())
+	out := []*gitlabTag{}
+	response, err := gitclient.DoJSON(ctx, client, "GET", path, nil, &out)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tags := []common.Tag{}
+	for _, tag := range out {
+		tags = append(tags, common.Tag{
+			Name:      tag.Name,
+			Sha:       tag.Target,
+			CommitSha: tag.Commit.ID,
+			Annotated: tag.Target != tag.Commit.ID,
+			Date:      tag.CreatedAt,
+		})
+	}
+	var nextPage int
+	if next := response.Header.Get("X-Next-Page"); next != "" {
+		fmt.Sscan(next, &nextPage)
+	}
+	return tags, nextPage, nil
+}
+
+func listTagsWithGit(config *common.GitConnectorParams, in *common.ListTagsInput) ([]common.Tag, int, error) {
+	gitClient, err := gitclient.New(config)
+	if err != nil {
+		return nil, 0, err
+	}
+	logrus.Info("Listing tags using git")
+	refs, err := gitClient.ListRefs()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	peeled := map[string]string{}
+	for _, ref := range refs {
+		if name := ref.Name().String(); strings.HasSuffix(name, gitclient.PeeledSuffix) {
+			peeled[strings.TrimSuffix(name, gitclient.PeeledSuffix)] = ref.Hash().String()
+		}
+	}
+
+	tags := []common.Tag{}
+	for _, ref := range refs {
+		name := ref.Name()
+		if !name.IsTag() || strings.HasSuffix(name.String(), gitclient.PeeledSuffix) || !common.MatchFilter(name.Short(), in.Filter) {
+			continue
+		}
+		tag := common.Tag{Name: name.Short(), Sha: ref.Hash().String(), CommitSha: ref.Hash().String()}
+		if commit, ok := peeled[name.String()]; ok {
+			tag.CommitSha = commit
+			tag.Annotated = true
+		}
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	start, end, nextPage := in.PageInput.Bounds(len(tags))
+	return tags[start:end], nextPage, nil
+}
@@ -13,7 +13,7 @@ import (
 	"github.com/harness/git-connector-cgi/gitclient"
 )
 
-func handleApiAccessValidation(provider common.Provider, config *common.APIAccess) error {
+func handleApiAccessValidation(provider common.Provider, repo string, config *common.APIAccess) error {
 	if config == nil {
 		return nil
 	}
@@ -31,7 +31,7 @@ func handleApiAccessValidation(provider common.Provider, config *common.APIAcces
 		return err
 	}
 
-	client, err := gitclient.GetGitClient(provider, config)
+	client, err := gitclient.GetGitClient(provider, repo, config)
 	if err != nil {
 		logrus.Errorf("Failed to create git provider client: %v", err)
 		return err
@@ -65,6 +65,14 @@ func validateAPIAccessConfig(config *common.APIAccess) error {
 			return errors.New("API Access token is missing")
 		}
 		break
+	case common.APIAccessUsernameToken:
+		if config.Username == "" {
+			return errors.New("API Access username is missing")
+		}
+		if config.Token == "" {
+			return errors.New("API Access token is missing")
+		}
+		break
 	case common.APIAccessGithubApp:
 		if config.GithubApp == nil {
 			return errors.New("Github App config is missing")
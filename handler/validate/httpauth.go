@@ -12,26 +12,29 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func handleRepoAccessHttpAuthValidation(repo string, config *common.HTTPAuth) error {
+func handleRepoAccessHttpAuthValidation(provider common.Provider, repo string, config *common.HTTPAuth) error {
 	if err := validateHttpAuthConfig(config); err != nil {
 		logrus.Errorf("Invalid HTTP Auth config provided: %v", err)
 		return err
 	}
-	if config.AuthMethod == common.HTTPAuthPassword || config.AuthMethod == common.HTTPAuthToken || config.AuthMethod == common.HTTPAuthAnonymous {
+	if config.AuthMethod == common.HTTPAuthPassword || config.AuthMethod == common.HTTPAuthToken || config.AuthMethod == common.HTTPAuthAnonymous || config.AuthMethod == common.HTTPAuthAWSCredentials {
 		gitClient := gitclient.NewHttp(repo, config)
 		return gitClient.ValidateWithHttp()
 	}
-	return handleProviderSpecificAuthValidation(config)
+	if provider == common.GenericGit {
+		return fmt.Errorf("HTTP Auth method %v is not supported for generic git provider", config.AuthMethod)
+	}
+	return handleProviderSpecificAuthValidation(repo, config)
 }
 
-func handleProviderSpecificAuthValidation(config *common.HTTPAuth) error {
+func handleProviderSpecificAuthValidation(repo string, config *common.HTTPAuth) error {
 
 	if config.AuthMethod == common.HTTPAuthGithubApp {
 		if config.GithubApp == nil {
 			logrus.Error("Github App details not provided")
 			return errors.New("Github App details not provided")
 		}
-		return validateGithubApp(context.Background(), &common.APIAccess{
+		return validateGithubApp(context.Background(), repo, &common.APIAccess{
 			AccessType: common.APIAccessGithubApp,
 			GithubApp:  config.GithubApp,
 		})
@@ -40,9 +43,9 @@ func handleProviderSpecificAuthValidation(config *common.HTTPAuth) error {
 	return errors.New("Invalid HTTP Auth method")
 }
 
-func validateGithubApp(ctx context.Context, config *common.APIAccess) error {
+func validateGithubApp(ctx context.Context, repo string, config *common.APIAccess) error {
 	logrus.Info("Validating repository access using Github app based auth")
-	client, err := gitclient.GetGitClient(common.Github, config)
+	client, err := gitclient.GetGitClient(common.Github, repo, config)
 	if err != nil {
 		logrus.Errorf("Failed to create github client: %v", err)
 		return err
@@ -73,6 +76,17 @@ func validateHttpAuthConfig(config *common.HTTPAuth) error {
 			return errors.New("HTTP Auth token is missing")
 		}
 	}
+	if config.AuthMethod == common.HTTPAuthAWSCredentials {
+		if config.AWS == nil {
+			return errors.New("AWS credentials are missing")
+		}
+		if config.AWS.AccessKeyId == "" {
+			return errors.New("AWS access key ID is missing")
+		}
+		if config.AWS.SecretAccessKey == "" {
+			return errors.New("AWS secret access key is missing")
+		}
+	}
 	if config.AuthMethod == common.HTTPAuthGithubApp {
 		if config.GithubApp == nil {
 			return errors.New("Github App is missing")
@@ -4,11 +4,17 @@ import (
 	"fmt"
 
 	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
 	"github.com/sirupsen/logrus"
 )
 
 func HandleValidate(provider common.Provider, config *common.GitConnectorParams) common.ValidationResponse {
-	if err := handleApiAccessValidation(provider, config.APIAccess); err != nil {
+	var skipped []string
+	if provider == common.GenericGit {
+		// Plain git servers have no API, only the repository access is validated
+		logrus.Info("Skipping API access validation for generic git provider")
+		skipped = append(skipped, common.APIAccessValidation)
+	} else if err := handleApiAccessValidation(provider, config.Repo, config.APIAccess); err != nil {
 		logrus.Errorf("Failed validating API access: %v", err)
 		return common.ValidationResponse{
 			Status:       common.Failure,
@@ -16,7 +22,7 @@ func HandleValidate(provider common.Provider, config *common.GitConnectorParams)
 			ErrorSummary: "Failed validating API access",
 		}
 	}
-	if err := handleRepoAccessValidation(config); err != nil {
+	if err := handleRepoAccessValidation(provider, config); err != nil {
 		logrus.Errorf("Failed validating repository access: %v", err)
 		return common.ValidationResponse{
 			Status:       common.Failure,
@@ -26,15 +32,22 @@ func HandleValidate(provider common.Provider, config *common.GitConnectorParams)
 	}
 	logrus.Info("Validation successful")
 	return common.ValidationResponse{
-		Status: common.Success,
+		Status:  common.Success,
+		Skipped: skipped,
 	}
 }
 
-func handleRepoAccessValidation(config *common.GitConnectorParams) error {
+func handleRepoAccessValidation(provider common.Provider, config *common.GitConnectorParams) error {
+	if provider == common.AzureRepo {
+		if _, err := gitclient.ParseAzureRepo(config.Repo); err != nil {
+			logrus.Errorf("Invalid Azure repository url: %v", err)
+			return err
+		}
+	}
 	authType := config.AuthType
 	switch authType {
 	case common.AuthTypeHttp:
-		return handleRepoAccessHttpAuthValidation(config.Repo, config.HTTPAuth)
+		return handleRepoAccessHttpAuthValidation(provider, config.Repo, config.HTTPAuth)
 	case common.AuthTypeSsh:
 		return handleRepoAccessSshAuthValidation(config.Repo, config.SSHAuth)
 	}
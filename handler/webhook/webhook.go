@@ -0,0 +1,293 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/harness/git-connector-cgi/common"
+	"github.com/harness/git-connector-cgi/gitclient"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func HandleCreateWebhook(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.WebhookResponse {
+	in, err := decodeWebhookInput(input)
+	if err != nil {
+		logrus.Errorf("Invalid create webhook params: %v", err)
+		return common.WebhookResponse{Response: common.NewFailureResponse("Invalid create webhook params", err)}
+	}
+
+	hook, created, err := createWebhook(provider, config, in)
+	if err != nil {
+		logrus.Errorf("Failed creating webhook: %v", err)
+		return common.WebhookResponse{Response: common.NewFailureResponse("Failed creating webhook", err)}
+	}
+	return common.WebhookResponse{
+		Response: common.NewSuccessResponse(),
+		Webhook:  hook,
+		Created:  created,
+	}
+}
+
+func HandleListWebhooks(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.ListWebhooksResponse {
+	in := new(common.ListWebhooksInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid list webhooks params: %v", err)
+		return common.ListWebhooksResponse{Response: common.NewFailureResponse("Invalid list webhooks params", err)}
+	}
+	in.Normalize()
+
+	hooks, nextPage, err := listWebhooks(provider, config, in)
+	if err != nil {
+		logrus.Errorf("Failed listing webhooks: %v", err)
+		return common.ListWebhooksResponse{Response: common.NewFailureResponse("Failed listing webhooks", err)}
+	}
+	return common.ListWebhooksResponse{
+		Response: common.NewSuccessResponse(),
+		Webhooks: hooks,
+		NextPage: nextPage,
+	}
+}
+
+func HandleUpdateWebhook(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.WebhookResponse {
+	in, err := decodeWebhookInput(input)
+	if err != nil {
+		logrus.Errorf("Invalid update webhook params: %v", err)
+		return common.WebhookResponse{Response: common.NewFailureResponse("Invalid update webhook params", err)}
+	}
+
+	hook, err := updateWebhook(provider, config, in)
+	if err != nil {
+		logrus.Errorf("Failed updating webhook: %v", err)
+		return common.WebhookResponse{Response: common.NewFailureResponse("Failed updating webhook", err)}
+	}
+	return common.WebhookResponse{
+		Response: common.NewSuccessResponse(),
+		Webhook:  hook,
+	}
+}
+
+func HandleDeleteWebhook(provider common.Provider, config *common.GitConnectorParams, input json.RawMessage) common.DeleteWebhookResponse {
+	in := new(common.DeleteWebhookInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		logrus.Errorf("Invalid delete webhook params: %v", err)
+		return common.DeleteWebhookResponse{Response: common.NewFailureResponse("Invalid delete webhook params", err)}
+	}
+	if in.ID == "" && in.Target == "" {
+		err := errors.New("webhook id or target is required")
+		logrus.Errorf("Invalid delete webhook params: %v", err)
+		return common.DeleteWebhookResponse{Response: common.NewFailureResponse("Invalid delete webhook params", err)}
+	}
+
+	deleted, err := deleteWebhook(provider, config, in)
+	if err != nil {
+		logrus.Errorf("Failed deleting webhook: %v", err)
+		return common.DeleteWebhookResponse{Response: common.NewFailureResponse("Failed deleting webhook", err)}
+	}
+	return common.DeleteWebhookResponse{
+		Response: common.NewSuccessResponse(),
+		Deleted:  deleted,
+	}
+}
+
+func decodeWebhookInput(input json.RawMessage) (*common.WebhookInput, error) {
+	in := new(common.WebhookInput)
+	if err := common.DecodeInput(input, in); err != nil {
+		return nil, err
+	}
+	if in.Target == "" {
+		return nil, errors.New("webhook target is required")
+	}
+	if len(in.Events) == 0 {
+		return nil, errors.New("webhook events are required")
+	}
+	return in, nil
+}
+
+// getWebhookClient returns the API client of the provider, as webhooks
+// cannot be managed through git.
+func getWebhookClient(provider common.Provider, config *common.GitConnectorParams) (*scm.Client, string, error) {
+	if !gitclient.HasAPIAccess(provider, config) {
+		return nil, "", status.Errorf(codes.FailedPrecondition, "API access is required to manage webhooks")
+	}
+	return gitclient.GetRepoClient(provider, config)
+}
+
+// createWebhook creates the webhook, or updates the existing webhook for
+// the same target so that registering a hook twice does not duplicate it.
+func createWebhook(provider common.Provider, config *common.GitConnectorParams, in *common.WebhookInput) (*common.Webhook, bool, error) {
+	client, repo, err := getWebhookClient(provider, config)
+	if err != nil {
+		return nil, false, err
+	}
+	ctx := context.Background()
+	existing, err := findWebhook(ctx, client, repo, in.Target)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		logrus.Infof("Webhook %s already exists for %s, updating it", existing.ID, in.Target)
+		hook, _, err := client.Repositories.UpdateHook(ctx, repo, existing.ID, hookInput(in))
+		if err != nil {
+			return nil, false, err
+		}
+		return convertHook(hook), false, nil
+	}
+
+	logrus.Infof("Creating webhook for %s", in.Target)
+	hook, _, err := client.Repositories.CreateHook(ctx, repo, hookInput(in))
+	if err != nil {
+		return nil, false, err
+	}
+	return convertHook(hook), true, nil
+}
+
+func listWebhooks(provider common.Provider, config *common.GitConnectorParams, in *common.ListWebhooksInput) ([]common.Webhook, int, error) {
+	client, repo, err := getWebhookClient(provider, config)
+	if err != nil {
+		return nil, 0, err
+	}
+	logrus.Info("Listing webhooks using the provider API")
+	out, response, err := client.Repositories.ListHooks(context.Background(), repo, scm.ListOptions{Page: in.Page, Size: in.PerPage})
+	if err != nil {
+		return nil, 0, err
+	}
+	hooks := []common.Webhook{}
+	for _, hook := range out {
+		hooks = append(hooks, *convertHook(hook))
+	}
+	return hooks, response.Page.Next, nil
+}
+
+// updateWebhook updates the webhook with the given id, or else the one
+// for the given target.
+func updateWebhook(provider common.Provider, config *common.GitConnectorParams, in *common.WebhookInput) (*common.Webhook, error) {
+	client, repo, err := getWebhookClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	id := in.ID
+	if id == "" {
+		existing, err := findWebhook(ctx, client, repo, in.Target)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, status.Errorf(codes.NotFound, "webhook for %s not found", in.Target)
+		}
+		id = existing.ID
+	}
+	logrus.Infof("Updating webhook %s", id)
+	hook, _, err := client.Repositories.UpdateHook(ctx, repo, id, hookInput(in))
+	if err != nil {
+		return nil, err
+	}
+	return convertHook(hook), nil
+}
+
+// deleteWebhook deletes the webhook with the given id, or else the one for
+// the given target. A webhook that does not exist is not an error.
+func deleteWebhook(provider common.Provider, config *common.GitConnectorParams, in *common.DeleteWebhookInput) (bool, error) {
+	client, repo, err := getWebhookClient(provider, config)
+	if err != nil {
+		return false, err
+	}
+	ctx := context.Background()
+	id := in.ID
+	if id == "" {
+		existing, err := findWebhook(ctx, client, repo, in.Target)
+		if err != nil {
+			return false, err
+		}
+		if existing == nil {
+			logrus.Infof("No webhook found for %s", in.Target)
+			return false, nil
+		}
+		id = existing.ID
+	}
+	logrus.Infof("Deleting webhook %s", id)
+	if res, err := client.Repositories.DeleteHook(ctx, repo, id); err != nil {
+		if gitclient.IsNotFound(res, err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// findWebhook returns the webhook for the target, going through every
+// page of webhooks, or nil when there is none.
+func findWebhook(ctx context.Context, client *scm.Client, repo, target string) (*scm.Hook, error) {
+	opts := scm.ListOptions{Page: 1, Size: common.MaxPerPage}
+	for {
+		hooks, response, err := client.Repositories.ListHooks(ctx, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, hook := range hooks {
+			if sameTarget(hook.Target, target) {
+				return hook, nil
+			}
+		}
+		if response == nil || response.Page.Next <= opts.Page {
+			return nil, nil
+		}
+		opts.Page = response.Page.Next
+	}
+}
+
+func sameTarget(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// hookInput maps the events onto the generic events of go-scm, passing
+// any other event through as a provider specific one.
+func hookInput(in *common.WebhookInput) *scm.HookInput {
+	input := &scm.HookInput{
+		Name:       in.Name,
+		Target:     in.Target,
+		Secret:     in.Secret,
+		SkipVerify: in.SkipSSLVerify,
+	}
+	for _, event := range in.Events {
+		switch strings.ToLower(event) {
+		case "push":
+			input.Events.Push = true
+		case "branch":
+			input.Events.Branch = true
+		case "tag":
+			input.Events.Tag = true
+		case "pull_request":
+			input.Events.PullRequest = true
+		case "pull_request_comment":
+			input.Events.PullRequestComment = true
+		case "review_comment":
+			input.Events.ReviewComment = true
+		case "issue":
+			input.Events.Issue = true
+		case "issue_comment":
+			input.Events.IssueComment = true
+		case "deployment":
+			input.Events.Deployment = true
+		default:
+			input.NativeEvents = append(input.NativeEvents, event)
+		}
+	}
+	return input
+}
+
+func convertHook(from *scm.Hook) *common.Webhook {
+	return &common.Webhook{
+		ID:            from.ID,
+		Name:          from.Name,
+		Target:        from.Target,
+		Events:        from.Events,
+		Active:        from.Active,
+		SkipSSLVerify: from.SkipVerify,
+	}
+}